@@ -12,12 +12,13 @@ import (
 	"strings"
 )
 
-// Boggler is an interface to a boggle board
+// Boggler is an interface to a boggle board.
+// Each cell holds a sequence of uppercase letters, like "A" or "QU", or the empty string if the cell is blocked.
 type Boggler interface {
 	Rows() int
 	Cols() int
-	Get(int, int) rune
-	GetLinear(int) rune
+	Get(int, int) string
+	GetLinear(int) string
 	Clone() Boggler
 	ArrayLinear() []string
 }
@@ -26,7 +27,7 @@ type Boggler interface {
 type BoggleBoard struct {
 	rows  int
 	cols  int
-	board [][]rune
+	board [][]string
 }
 
 // Rows implements Boggler's interface
@@ -40,12 +41,12 @@ func (bb *BoggleBoard) Cols() int {
 }
 
 // Get implements Boggler's interface
-func (bb *BoggleBoard) Get(i int, j int) rune {
+func (bb *BoggleBoard) Get(i int, j int) string {
 	return bb.board[i][j]
 }
 
 // GetLinear implements Boggler's interface
-func (bb *BoggleBoard) GetLinear(k int) rune {
+func (bb *BoggleBoard) GetLinear(k int) string {
 	return bb.board[k/bb.cols][k%bb.cols]
 }
 
@@ -53,29 +54,26 @@ func (bb *BoggleBoard) GetLinear(k int) rune {
 func (bb *BoggleBoard) ArrayLinear() []string {
 	r := make([]string, bb.Rows()*bb.Cols())
 	for i := range r {
-		r[i] = string(bb.GetLinear(i))
-		if r[i] == "Q" {
-			r[i] = "Qu"
-		}
+		r[i] = faceString(bb.GetLinear(i))
 	}
 	return r
 }
 
 // Clone implements Boggler's interface
 func (bb *BoggleBoard) Clone() Boggler {
-	board := make([][]rune, len(bb.board))
+	board := make([][]string, len(bb.board))
 	for i := range bb.board {
-		board[i] = make([]rune, len(bb.board[i]))
+		board[i] = make([]string, len(bb.board[i]))
 		copy(board[i], bb.board[i])
 	}
 	return &BoggleBoard{rows: bb.rows, cols: bb.cols, board: board}
 }
 
-// DiceBoard stores the actual dice used to make the board (instead of storing the runes)
+// DiceBoard stores the actual dice used to make the board (instead of storing the letters)
 type DiceBoard struct {
 	rows int
 	cols int
-	dice []string
+	dice []Die
 	die  [][]int
 	face [][]int
 }
@@ -91,12 +89,12 @@ func (bb *DiceBoard) Cols() int {
 }
 
 // Get implements Boggler's interface
-func (bb *DiceBoard) Get(i int, j int) rune {
-	return rune(bb.dice[bb.die[i][j]][bb.face[i][j]])
+func (bb *DiceBoard) Get(i int, j int) string {
+	return bb.dice[bb.die[i][j]][bb.face[i][j]]
 }
 
 // GetLinear implements Boggler's interface
-func (bb *DiceBoard) GetLinear(k int) rune {
+func (bb *DiceBoard) GetLinear(k int) string {
 	return bb.Get(k/bb.cols, k%bb.cols)
 }
 
@@ -104,10 +102,7 @@ func (bb *DiceBoard) GetLinear(k int) rune {
 func (bb *DiceBoard) ArrayLinear() []string {
 	r := make([]string, bb.Rows()*bb.Cols())
 	for i := range r {
-		r[i] = string(bb.GetLinear(i))
-		if r[i] == "Q" {
-			r[i] = "Qu"
-		}
+		r[i] = faceString(bb.GetLinear(i))
 	}
 	return r
 }
@@ -116,7 +111,7 @@ func (bb *DiceBoard) ArrayLinear() []string {
 func (bb *DiceBoard) Clone() Boggler {
 	die := make([][]int, len(bb.die))
 	face := make([][]int, len(bb.face))
-	dice := make([]string, len(bb.dice))
+	dice := make([]Die, len(bb.dice))
 	copy(dice, bb.dice)
 	for i := range bb.die {
 		die[i] = make([]int, len(bb.die[i]))
@@ -172,48 +167,48 @@ var frequencies = []float64{
 	0.01974, 0.00074,
 }
 
-func throwDice(d []string) []rune {
-	f := make([]rune, len(d))
-	a := make([]string, len(d))
+func throwDice(rng *rand.Rand, d []Die) []string {
+	f := make([]string, len(d))
+	a := make([]Die, len(d))
 	copy(a, d)
 	for i := range a {
-		j := rand.Intn(i + 1)
+		j := rng.Intn(i + 1)
 		a[i], a[j] = a[j], a[i]
 	}
 	for i, die := range a {
-		idx := rand.Intn(len(die))
-		f[i] = rune(die[idx])
+		idx := rng.Intn(len(die))
+		f[i] = die[idx]
 	}
 	return f
 }
 
-func copyShuffle(b []int) []int {
+func copyShuffle(rng *rand.Rand, b []int) []int {
 	a := make([]int, len(b))
 	copy(a, b)
 	for i := range a {
-		j := rand.Intn(i + 1)
+		j := rng.Intn(i + 1)
 		a[i], a[j] = a[j], a[i]
 	}
 	return a
 }
 
-func shuffledInts(n int) []int {
+func shuffledInts(rng *rand.Rand, n int) []int {
 	a := make([]int, n)
 	for i := range a {
 		a[i] = i
 	}
 	for i := range a {
-		j := rand.Intn(i + 1)
+		j := rng.Intn(i + 1)
 		a[i], a[j] = a[j], a[i]
 	}
 	return a
 }
 
-func newBoggleBoard(rows int, cols int, dice []string) *BoggleBoard {
-	board := make([][]rune, rows)
-	faces := throwDice(dice)
+func newBoggleBoard(rng *rand.Rand, rows int, cols int, dice []Die) *BoggleBoard {
+	board := make([][]string, rows)
+	faces := throwDice(rng, dice)
 	for i := 0; i < rows; i++ {
-		board[i] = make([]rune, cols)
+		board[i] = make([]string, cols)
 		for j := 0; j < cols; j++ {
 			board[i][j] = faces[cols*i+j]
 		}
@@ -221,43 +216,40 @@ func newBoggleBoard(rows int, cols int, dice []string) *BoggleBoard {
 	return &BoggleBoard{rows: rows, cols: cols, board: board}
 }
 
-func newDiceBoard(rows int, cols int, dice []string) *DiceBoard {
-	diceorder := shuffledInts(len(dice))
+func newDiceBoard(rng *rand.Rand, rows int, cols int, dice []Die) *DiceBoard {
+	diceorder := shuffledInts(rng, len(dice))
 	die := make([][]int, rows)
 	face := make([][]int, rows)
-	l := len(dice[0])
 	for i := 0; i < rows; i++ {
 		die[i] = make([]int, cols)
-		face[i] = make([]int, len(dice[0]))
+		face[i] = make([]int, cols)
 		for j := 0; j < cols; j++ {
 			die[i][j] = diceorder[cols*i+j]
-			face[i][j] = rand.Intn(l)
+			face[i][j] = rng.Intn(len(dice[die[i][j]]))
 		}
 	}
 	return &DiceBoard{rows: rows, cols: cols, dice: dice, die: die, face: face}
 }
 
 // NewBoggleBoard initializes a random 4-by-4 board by rolling the Hasbro dice.
-func NewBoggleBoard() *BoggleBoard {
-	return newBoggleBoard(4, 4, boggle1992)
+// Board generators draw only from the given source, so they are safe to call concurrently with different sources.
+func NewBoggleBoard(rng *rand.Rand) *BoggleBoard {
+	return newBoggleBoard(rng, 4, 4, diceSets["1992"])
 }
 
 // NewBoggleBoard1983 initializes a random 4-by-4 board by rolling the 1983 Hasbro dice.
-// This function is not threadsafe.
-func NewBoggleBoard1983() *BoggleBoard {
-	return newBoggleBoard(4, 4, boggle1983)
+func NewBoggleBoard1983(rng *rand.Rand) *BoggleBoard {
+	return newBoggleBoard(rng, 4, 4, diceSets["1983"])
 }
 
 // NewBoggleBoardMaster initializes a random 5-by-5 board by rolling the Boggle Master/Boggle Deluxe dice.
-// This function is not threadsafe.
-func NewBoggleBoardMaster() *BoggleBoard {
-	return newBoggleBoard(5, 5, boggleMaster)
+func NewBoggleBoardMaster(rng *rand.Rand) *BoggleBoard {
+	return newBoggleBoard(rng, 5, 5, diceSets["master"])
 }
 
 // NewBoggleBoardBig initializes a random 5-by-5 board by rolling the Big Boggle dice.
-// This function is not threadsafe.
-func NewBoggleBoardBig() *BoggleBoard {
-	return newBoggleBoard(5, 5, boggleBig)
+func NewBoggleBoardBig(rng *rand.Rand) *BoggleBoard {
+	return newBoggleBoard(rng, 5, 5, diceSets["big"])
 }
 
 func (bb *BoggleBoard) String() string {
@@ -265,12 +257,7 @@ func (bb *BoggleBoard) String() string {
 	bf.WriteString(fmt.Sprintf("%d %d\n", bb.rows, bb.cols))
 	for _, br := range bb.board {
 		for _, bc := range br {
-			bf.WriteRune(bc)
-			if bc == 'Q' {
-				bf.WriteString("u ")
-			} else {
-				bf.WriteString("  ")
-			}
+			bf.WriteString(fmt.Sprintf("%-3s", faceString(bc)))
 		}
 		bf.WriteString("\n")
 	}
@@ -282,13 +269,7 @@ func (bb *DiceBoard) String() string {
 	bf.WriteString(fmt.Sprintf("%d %d\n", bb.rows, bb.cols))
 	for i := 0; i < bb.Rows(); i++ {
 		for j := 0; j < bb.Cols(); j++ {
-			bc := bb.Get(i, j)
-			bf.WriteRune(bc)
-			if bc == 'Q' {
-				bf.WriteString("u ")
-			} else {
-				bf.WriteString("  ")
-			}
+			bf.WriteString(fmt.Sprintf("%-3s", faceString(bb.Get(i, j))))
 		}
 		bf.WriteString("\n")
 	}
@@ -321,27 +302,20 @@ func (bb *BoggleBoard) UnmarshalText(text []byte) error {
 		return err
 	}
 
-	board := make([][]rune, rows)
+	board := make([][]string, rows)
 	for i := 0; i < rows; i++ {
-		board[i] = make([]rune, cols)
+		board[i] = make([]string, cols)
 		for j := 0; j < cols; j++ {
 			if !scanner.Scan() {
 				return errors.New("ran out of letters when scanning text")
 			}
 
-			letter := strings.ToUpper(scanner.Text())
-			if letter == "QU" {
-				letter = "Q"
+			letter, err := parseFace(scanner.Text())
+			if err != nil {
+				return err
 			}
 
-			if len(letter) != 1 {
-				return fmt.Errorf("invalid character: %s", letter)
-			}
-			if strings.Index(alphabet, letter) == -1 {
-				return fmt.Errorf("invalid character: %s", letter)
-			}
-
-			board[i][j] = rune(letter[0])
+			board[i][j] = letter
 		}
 	}
 
@@ -373,7 +347,7 @@ func ReadBoggleBoard(filename string) (*BoggleBoard, error) {
 	return &bb, nil
 }
 
-func randomIndex(p []float64) (int, error) {
+func randomIndex(rng *rand.Rand, p []float64) (int, error) {
 	sum := 0.
 	for _, v := range p {
 		if v < 0. {
@@ -382,7 +356,7 @@ func randomIndex(p []float64) (int, error) {
 		sum += v
 	}
 	for {
-		r := rand.Float64()
+		r := rng.Float64()
 		s2 := 0.
 		for i, v := range p {
 			s2 += v / sum
@@ -394,30 +368,40 @@ func randomIndex(p []float64) (int, error) {
 }
 
 // NewBoggleBoardRandom creates a random M-by-N board according to the frequency of letters in the English language
-func NewBoggleBoardRandom(rows int, cols int) *BoggleBoard {
-	board := make([][]rune, rows)
+func NewBoggleBoardRandom(rng *rand.Rand, rows int, cols int) *BoggleBoard {
+	board := make([][]string, rows)
 	for i := 0; i < rows; i++ {
-		board[i] = make([]rune, cols)
+		board[i] = make([]string, cols)
 		for j := 0; j < cols; j++ {
-			idx, _ := randomIndex(frequencies)
-			board[i][j] = rune(alphabet[idx])
+			idx, _ := randomIndex(rng, frequencies)
+			board[i][j], _ = parseFace(alphabet[idx : idx+1])
 		}
 	}
 
 	return &BoggleBoard{rows: rows, cols: cols, board: board}
 }
 
-// DictShuffle shuffles the dice according to the 2-letter occurance frequencies
-func (bb *DiceBoard) DictShuffle(adjList [][]int, f2 [][]float64) {
+// DictShuffle shuffles the dice according to the 2-letter occurance frequencies.
+// It returns the cells that were re-thrown; no other cell changes.
+func (bb *DiceBoard) DictShuffle(rng *rand.Rand, adjList [][]int, f2 [][]float64) []int {
 	weights := make([]float64, bb.rows*bb.cols)
 	sum := 0.
 	for i, adjl := range adjList {
 		for _, adj := range adjl {
-			r1 := bb.GetLinear(i)
-			r2 := bb.GetLinear(adj)
-			weights[i] += f2[r1-'A'][r2-'A']
+			// Multi-letter cells join at the last letter of one and the first letter of the other
+			l1 := bb.GetLinear(i)
+			l2 := bb.GetLinear(adj)
+			if l1 == "" || l2 == "" {
+				continue
+			}
+			weights[i] += f2[l1[len(l1)-1]-'A'][l2[0]-'A']
+		}
+		if len(adjl) > 0 {
+			weights[i] = 1. - weights[i]/float64(len(adjl))
+		} else {
+			// A cell without neighbors spells nothing, so it is as good a cell to re-throw as any
+			weights[i] = 1.
 		}
-		weights[i] = 1. - weights[i]/float64(len(adjl))
 		sum += weights[i]
 	}
 
@@ -430,14 +414,14 @@ func (bb *DiceBoard) DictShuffle(adjList [][]int, f2 [][]float64) {
 	var rethrow []int
 	for len(rethrow) == 0 {
 		for i, w := range weights {
-			if rand.Float64() < w {
+			if rng.Float64() < w {
 				rethrow = append(rethrow, i)
 			}
 		}
 	}
 
 	// Reassign by shuffling
-	thrown := copyShuffle(rethrow)
+	thrown := copyShuffle(rng, rethrow)
 	for i := range rethrow {
 		i1 := rethrow[i]
 		i2 := thrown[i]
@@ -447,31 +431,35 @@ func (bb *DiceBoard) DictShuffle(adjList [][]int, f2 [][]float64) {
 		r2 := i2 / bb.Cols()
 		c2 := i2 % bb.Cols()
 
-		// Flip
+		// Flip, keeping each face with its die since dice may have different numbers of faces
 		bb.die[r1][c1], bb.die[r2][c2] = bb.die[r2][c2], bb.die[r1][c1]
+		bb.face[r1][c1], bb.face[r2][c2] = bb.face[r2][c2], bb.face[r1][c1]
 
 		// Roll
-		l := len(bb.dice[0])
-		bb.face[r1][c1] = rand.Intn(l)
+		l := len(bb.dice[bb.die[r1][c1]])
+		bb.face[r1][c1] = rng.Intn(l)
 	}
 
+	return rethrow
 }
 
-// NewBoggleBoardArray Initialize board from the given 2D character array.
-func NewBoggleBoardArray(board [][]rune) (*BoggleBoard, error) {
+// NewBoggleBoardArray Initialize board from the given 2D array of cells.
+// Cells are read as in UnmarshalText, so "Q" and "Qu" both become "QU" and "." is a blocked cell.
+func NewBoggleBoardArray(board [][]string) (*BoggleBoard, error) {
 	rows := len(board)
-	bb := make([][]rune, rows)
+	bb := make([][]string, rows)
 	cols := len(board[0])
 	for i, bc := range board {
 		if len(bc) != cols {
 			return nil, errors.New("array is ragged")
 		}
-		bb[i] = make([]rune, cols)
+		bb[i] = make([]string, cols)
 		for j, br := range bc {
-			if strings.Index(alphabet, string(br)) == -1 {
-				return nil, fmt.Errorf("invalid character: %c", bc)
+			letter, err := parseFace(br)
+			if err != nil {
+				return nil, err
 			}
-			bb[i][j] = rune(br)
+			bb[i][j] = letter
 		}
 	}
 	return &BoggleBoard{rows: rows, cols: cols, board: bb}, nil
@@ -2,6 +2,7 @@ package main
 
 import (
 	"io/ioutil"
+	"math/rand"
 	"path/filepath"
 	"testing"
 )
@@ -43,14 +44,40 @@ func TestBoggleBoard(t *testing.T) {
 func BenchmarkShuffle(b *testing.B) {
 	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
 	adjList := buildAdjList(4, 4)
-	f2, err := frequencyCount(dictfile, 16)
+	f2, err := frequencyCount(dictfile, ClassicRule.MinLength(), 16)
 	if err != nil {
 		b.Fatal(err)
 	}
-	bb := newDiceBoard(4, 4, boggle1992)
+	rng := rand.New(rand.NewSource(1))
+	bb := newDiceBoard(rng, 4, 4, diceSets["1992"])
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		bb.DictShuffle(adjList, f2)
+		bb.DictShuffle(rng, adjList, f2)
+	}
+}
+
+func TestDictShuffleIsolatedCell(t *testing.T) {
+	f2 := make([][]float64, 26)
+	for i := range f2 {
+		f2[i] = make([]float64, 26)
+	}
+	rng := rand.New(rand.NewSource(1))
+	bb := newDiceBoard(rng, 1, 3, diceSets["1992"][:3])
+
+	// The last cell has no neighbors, which once made every weight NaN and the shuffle loop forever
+	adjList := [][]int{{1}, {0}, {}}
+	for i := 0; i < 100; i++ {
+		if changed := bb.DictShuffle(rng, adjList, f2); len(changed) == 0 {
+			t.Fatal("no cells re-thrown")
+		}
+	}
+}
+
+func TestBoggleBoardSeeded(t *testing.T) {
+	a := NewBoggleBoardMaster(rand.New(rand.NewSource(42)))
+	b := NewBoggleBoardMaster(rand.New(rand.NewSource(42)))
+	if a.String() != b.String() {
+		t.Errorf("boards rolled from the same seed differ:\n%s\n%s", a, b)
 	}
 }
@@ -0,0 +1,610 @@
+package main
+
+import (
+	"math/bits"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// boundSearch performs an exhaustive branch-and-bound search for the highest-scoring board.
+// Each cell holds a set of tiles (single letters or letter sequences like "QU"), at first one of a
+// few buckets of tiles, and the sets are split one cell at a time until every cell holds a single
+// tile.  An upper bound on the score of every board the sets allow is kept in a bound tree, which
+// is restricted rather than rebuilt as cells are split.  Any sets that cannot beat the best
+// complete board found so far are pruned.  Each worker has its own boundSearch, sharing the best.
+type boundSearch struct {
+	solver  *boggleSolver
+	scorer  *scorer
+	tiles   []string
+	buckets []uint64
+	order   []int
+	canon   *canonicalizer
+	best    *boundBest
+
+	// class is the number of the assignment of buckets to cells being searched
+	class int
+	nodes int
+	tree  boundTree
+}
+
+// boundBest is the best board found by any worker.  A board beats another with a higher score, or
+// with the same score and a lower-numbered assignment of buckets, so that the board found does not
+// depend on how the workers are scheduled.
+type boundBest struct {
+	mu    sync.Mutex
+	score int
+	// class is the number of the assignment of buckets holding board, or -1 before any board reaches the floor
+	class int
+	board *BoggleBoard
+}
+
+// beatable reports whether a board of the given class scoring bound could beat the best board
+func (b *boundBest) beatable(bound, class int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bound > b.score || (bound == b.score && class < b.class)
+}
+
+// record makes a board the best if it beats the best board
+func (b *boundBest) record(score, class int, board *BoggleBoard) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if score > b.score || (score == b.score && class < b.class) {
+		b.score = score
+		b.class = class
+		b.board = board
+	}
+}
+
+// found returns the best score, or zero if no board has reached the floor
+func (b *boundBest) found() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.board == nil {
+		return 0
+	}
+	return b.score
+}
+
+// boundResult is the outcome of a branch-and-bound search.
+type boundResult struct {
+	// Board is the highest scoring board found, or nil if no board scores at least the requested floor.
+	Board *BoggleBoard
+	// Score is the score of Board.
+	Score int
+	// Nodes is the number of sets of boards visited during the search.
+	Nodes int
+}
+
+// boundProgress describes how far a branch-and-bound search has got.
+type boundProgress struct {
+	// Classes is the number of assignments of buckets to cells searched so far, out of Total.
+	Classes, Total int
+	// Best is the highest score found so far, or zero if no board has reached the floor.
+	Best  int
+	Nodes int
+}
+
+// maxBoundTiles is the most tiles maximize can place, and the most cells its boards may have
+const maxBoundTiles = 64
+
+// maximize searches every board that can be made from the given tiles for the one with the
+// maximum score, with the given number of workers.  Each tile may be used any number of times.
+// Boards scoring less than floor are never reported, and a floor near the maximum lets the search
+// prune more.  Because the bound never underestimates and every board that survives it is scored
+// exactly, the board returned is a certified maximum for the solver's dictionary.  If progress is
+// not nil it is called each time an assignment of buckets to cells has been searched.  There may
+// be at most maxBoundTiles tiles and cells.
+func (bs *boggleSolver) maximize(tiles []string, floor int, workers int, progress func(boundProgress)) boundResult {
+	proto := boundSearch{
+		solver:  bs,
+		tiles:   tiles,
+		buckets: bucketTiles(tiles, boundBuckets),
+		order:   assignmentOrder(bs.adjList),
+		canon:   newCanonicalizer(bs.rows, bs.cols, bs.adjList),
+		best:    &boundBest{score: floor - 1, class: -1},
+	}
+	total := 1
+	for range bs.adjList {
+		total *= len(proto.buckets)
+	}
+
+	type job struct {
+		class int
+		// skipped is the number of assignments passed over, as symmetric to earlier ones, since the last job
+		skipped int
+	}
+	type done struct {
+		classes, nodes int
+	}
+	jobs := make(chan job, workers)
+	dones := make(chan done, workers)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s := proto
+		s.scorer = newScorer(bs)
+		s.seed()
+		skipped := 0
+		for class := 0; class < total; class++ {
+			if !s.canonical(s.classSets(class)) {
+				skipped++
+				continue
+			}
+			jobs <- job{class, skipped}
+			skipped = 0
+		}
+		close(jobs)
+		dones <- done{classes: skipped, nodes: s.nodes}
+	}()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := proto
+			s.scorer = newScorer(bs)
+			for j := range jobs {
+				s.nodes = 0
+				s.searchClass(j.class)
+				dones <- done{classes: j.skipped + 1, nodes: s.nodes}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(dones)
+	}()
+
+	var p boundProgress
+	p.Total = total
+	for d := range dones {
+		p.Classes += d.classes
+		p.Nodes += d.nodes
+		if progress != nil {
+			p.Best = proto.best.found()
+			progress(p)
+		}
+	}
+	best := proto.best
+	if best.board == nil {
+		return boundResult{Nodes: p.Nodes}
+	}
+	return boundResult{Board: best.board, Score: best.score, Nodes: p.Nodes}
+}
+
+// seedClimbs is the number of random boards seed climbs from
+const seedClimbs = 50
+
+// seed starts the search from a good board, found by climbing from random boards one cell at a time to
+// the best tile for the cell until no single tile improves the board.  The higher the best score, the
+// more the search prunes; every board it does not beat is still ruled out by the bound.
+func (s *boundSearch) seed() {
+	rng := rand.New(rand.NewSource(1))
+	n := len(s.solver.adjList)
+	sets := make([]uint64, n)
+	for climb := 0; climb < seedClimbs; climb++ {
+		for p := range sets {
+			sets[p] = 1 << uint(rng.Intn(len(s.tiles)))
+		}
+		score := s.scorer.score(s.board(sets))
+		for improved := true; improved; {
+			improved = false
+			for p := range sets {
+				tile := sets[p]
+				for i := range s.tiles {
+					sets[p] = 1 << uint(i)
+					if sc := s.scorer.score(s.board(sets)); sc > score {
+						score = sc
+						tile = sets[p]
+						improved = true
+					}
+				}
+				sets[p] = tile
+			}
+		}
+		s.best.record(score, s.classOf(sets), s.board(sets))
+	}
+}
+
+// boundBuckets is the number of buckets the tiles are divided into before the search begins.  More
+// buckets make each bound tree smaller and tighter but leave more assignments of buckets to search.
+const boundBuckets = 4
+
+// bucketTiles divides the tiles into at most n buckets of nearly equal size, the vowels apart from
+// the consonants.  Tiles that can stand in for one another make the tightest buckets, since the
+// bound takes the best tile of a bucket separately along every path.
+func bucketTiles(tiles []string, n int) []uint64 {
+	var vowels, consonants []int
+	for i, t := range tiles {
+		if isVowel(t[0]) {
+			vowels = append(vowels, i)
+		} else {
+			consonants = append(consonants, i)
+		}
+	}
+	if len(vowels) == 0 || len(consonants) == 0 || n < 2 {
+		return splitTiles(append(vowels, consonants...), n)
+	}
+	// The vowels take the share of the buckets their number deserves, but at least one
+	v := min(max(n*len(vowels)/len(tiles), 1), n-1)
+	return append(splitTiles(vowels, v), splitTiles(consonants, n-v)...)
+}
+
+// splitTiles divides tile indexes into at most n runs of nearly equal size
+func splitTiles(indexes []int, n int) []uint64 {
+	n = min(n, len(indexes))
+	buckets := make([]uint64, n)
+	for k, i := range indexes {
+		buckets[k*n/len(indexes)] |= 1 << uint(i)
+	}
+	return buckets
+}
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'A', 'E', 'I', 'O', 'U':
+		return true
+	}
+	return false
+}
+
+// assignmentOrder returns the cells sorted so that the most connected cells are split first.
+// Well-connected cells contribute the most paths, so fixing them early tightens the bound fastest.
+func assignmentOrder(adjList [][]int) []int {
+	order := make([]int, len(adjList))
+	for i := range order {
+		order[i] = i
+	}
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && len(adjList[order[j]]) > len(adjList[order[j-1]]); j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+	return order
+}
+
+// classSets returns the sets of tiles of the numbered assignment of buckets to cells, which counts in
+// base len(s.buckets) with the first cell the most significant digit
+func (s *boundSearch) classSets(class int) []uint64 {
+	sets := make([]uint64, len(s.solver.adjList))
+	for p := len(sets) - 1; p >= 0; p-- {
+		sets[p] = s.buckets[class%len(s.buckets)]
+		class /= len(s.buckets)
+	}
+	return sets
+}
+
+// classOf returns the number of the assignment of buckets holding the sets' tiles
+func (s *boundSearch) classOf(sets []uint64) int {
+	class := 0
+	for _, set := range sets {
+		b := 0
+		for s.buckets[b]&set == 0 {
+			b++
+		}
+		class = class*len(s.buckets) + b
+	}
+	return class
+}
+
+// searchClass searches the numbered assignment of buckets to cells
+func (s *boundSearch) searchClass(class int) {
+	s.class = class
+	sets := s.classSets(class)
+	s.tree.release(boundMark{})
+	s.search(s.build(sets), sets)
+}
+
+// canonical reports whether no symmetry of the topology makes the sets of the cells lexicographically
+// smaller, comparing them as numbers.  Assignments that a symmetry turns into an earlier one hold the
+// same boards, moved about, so only the first is searched.
+func (s *boundSearch) canonical(sets []uint64) bool {
+	for _, perm := range s.canon.perms[1:] {
+		for p := range perm {
+			if a, b := sets[perm[p]], sets[p]; a != b {
+				if a < b {
+					return false
+				}
+				break
+			}
+		}
+	}
+	return true
+}
+
+// search splits the first cell in the assignment order holding more than one tile into each of its
+// tiles, searching the most promising first, and scores the board once every cell holds a single tile.
+func (s *boundSearch) search(tree int32, sets []uint64) {
+	s.nodes++
+	if !s.best.beatable(s.tree.upper(tree), s.class) {
+		return
+	}
+
+	k := 0
+	for k < len(s.order) && bits.OnesCount64(sets[s.order[k]]) == 1 {
+		k++
+	}
+	if k == len(s.order) {
+		board := s.board(sets)
+		s.best.record(s.scorer.score(board), s.class, board)
+		return
+	}
+
+	p := s.order[k]
+	set := sets[p]
+	bounds := make([]int, bits.OnesCount64(set))
+	s.tree.sumBounds(tree, p, set, bounds, 0)
+	tiles := make([]uint64, 0, len(bounds))
+	for rest := set; rest != 0; rest &= rest - 1 {
+		tiles = append(tiles, rest&-rest)
+	}
+	ranks := make([]int, len(bounds))
+	for i := range ranks {
+		ranks[i] = i
+	}
+	sort.SliceStable(ranks, func(i, j int) bool { return bounds[ranks[i]] > bounds[ranks[j]] })
+	for _, i := range ranks {
+		// The best board may have improved since the bounds were found
+		if !s.best.beatable(bounds[i], s.class) {
+			break
+		}
+		sets[p] = tiles[i]
+		mark := s.tree.mark()
+		s.search(s.tree.restrictSum(tree, p, tiles[i]), sets)
+		s.tree.release(mark)
+	}
+	sets[p] = set
+}
+
+// board returns the board whose cells hold the single tiles of the sets
+func (s *boundSearch) board(sets []uint64) *BoggleBoard {
+	rows := s.solver.rows
+	cols := s.solver.cols
+	board := make([][]string, rows)
+	for i := range board {
+		board[i] = make([]string, cols)
+		for j := range board[i] {
+			board[i][j] = s.tiles[bits.TrailingZeros64(sets[i*cols+j])]
+		}
+	}
+	return &BoggleBoard{rows: rows, cols: cols, board: board}
+}
+
+// A bound tree lays out every path a word may take over the board when each cell may hold any tile
+// of its set.  A sum node is a prefix spelled along some path, holding the points of the prefix, if
+// it is a word, and a choice node for each cell the path may continue to.  A choice node holds a sum
+// node for each tile of its cell's set that continues a word.  Any board the sets allow scores at
+// most the bound of the root: the points of a sum plus the bounds of its choices, and the best
+// bound among the tiles of a choice.  Words found along several paths are counted once for each.
+// Subtrees that cannot score are left out, and subtrees without the cell being split are shared
+// between a tree and its restrictions.
+//
+// The nodes of every tree are kept in flat slices and refer to each other by index, so that the
+// garbage collector has no pointers to follow.  The search restricts trees depth first, so the nodes
+// of a restriction are released, by truncating the slices, once its search is done.
+type boundTree struct {
+	nodes []boundNode
+	// edges holds the children of each node, one run after another
+	edges []int32
+	// pending holds the children of the nodes being built
+	pending []int32
+	// scratch holds a vector of bounds for each level of the tree, for sumBounds
+	scratch [][]int
+}
+
+type boundNode struct {
+	// cells has a bit set for the cell of this and every choice below
+	cells uint64
+	bound int32
+	// points is the value of a sum's prefix, and label the tile of a sum or the cell of a choice
+	points int32
+	label  int32
+	// first and count give the node's run of edges
+	first, count int32
+}
+
+// boundMark records the length of a bound tree's slices, to release every node added since
+type boundMark struct {
+	nodes, edges int
+}
+
+func (t *boundTree) mark() boundMark {
+	return boundMark{len(t.nodes), len(t.edges)}
+}
+
+func (t *boundTree) release(m boundMark) {
+	t.nodes = t.nodes[:m.nodes]
+	t.edges = t.edges[:m.edges]
+}
+
+// upper returns the bound of a tree, which is zero for a tree that cannot score, numbered -1
+func (t *boundTree) upper(i int32) int {
+	if i < 0 {
+		return 0
+	}
+	return int(t.nodes[i].bound)
+}
+
+// child adds the node numbered i to the children of the node being built, returning its bound and cells
+func (t *boundTree) child(i int32) (int32, uint64) {
+	t.pending = append(t.pending, i)
+	return t.nodes[i].bound, t.nodes[i].cells
+}
+
+// finish adds a node whose children are those pending since mark, returning its number
+func (t *boundTree) finish(n boundNode, mark int) int32 {
+	n.first = int32(len(t.edges))
+	n.count = int32(len(t.pending) - mark)
+	t.edges = append(t.edges, t.pending[mark:]...)
+	t.pending = t.pending[:mark]
+	t.nodes = append(t.nodes, n)
+	return int32(len(t.nodes) - 1)
+}
+
+// build returns the bound tree of the boards that the sets of tiles allow, or -1 if none of them scores
+func (s *boundSearch) build(sets []uint64) int32 {
+	t := &s.tree
+	mark := len(t.pending)
+	var root boundNode
+	for p := range sets {
+		if c := s.buildChoice(sets, p, s.solver.dictionary.Root(), 0); c >= 0 {
+			bound, cells := t.child(c)
+			root.bound += bound
+			root.cells |= cells
+		}
+	}
+	if root.bound == 0 {
+		return -1
+	}
+	return t.finish(root, mark)
+}
+
+func (s *boundSearch) buildChoice(sets []uint64, p int, x DAWGNode, visited uint64) int32 {
+	t := &s.tree
+	mark := len(t.pending)
+	c := boundNode{label: int32(p), cells: 1 << uint(p)}
+	visited |= 1 << uint(p)
+	for rest := sets[p]; rest != 0; rest &= rest - 1 {
+		tile := bits.TrailingZeros64(rest)
+		next, ok := x.Subtrie(s.tiles[tile])
+		if !ok {
+			continue
+		}
+		sumMark := len(t.pending)
+		n := boundNode{label: int32(tile), points: int32(next.RootValue())}
+		n.bound = n.points
+		if !next.Leaf() {
+			for _, p2 := range s.solver.adjList[p] {
+				if visited&(1<<uint(p2)) != 0 {
+					continue
+				}
+				if c2 := s.buildChoice(sets, p2, next, visited); c2 >= 0 {
+					bound, cells := t.child(c2)
+					n.bound += bound
+					n.cells |= cells
+				}
+			}
+		}
+		if n.bound > 0 {
+			bound, cells := t.child(t.finish(n, sumMark))
+			c.bound = max(c.bound, bound)
+			c.cells |= cells
+		}
+	}
+	if c.bound == 0 {
+		return -1
+	}
+	return t.finish(c, mark)
+}
+
+// restrictSum returns the bound tree of the boards with cell p holding only the tiles of set, or -1
+// if none of them scores
+func (t *boundTree) restrictSum(i int32, p int, set uint64) int32 {
+	if i < 0 {
+		return i
+	}
+	n := t.nodes[i]
+	if n.cells&(1<<uint(p)) == 0 {
+		return i
+	}
+	mark := len(t.pending)
+	r := boundNode{label: n.label, points: n.points, bound: n.points}
+	for e := n.first; e < n.first+n.count; e++ {
+		if c := t.restrictChoice(t.edges[e], p, set); c >= 0 {
+			bound, cells := t.child(c)
+			r.bound += bound
+			r.cells |= cells
+		}
+	}
+	if r.bound == 0 {
+		return -1
+	}
+	return t.finish(r, mark)
+}
+
+func (t *boundTree) restrictChoice(i int32, p int, set uint64) int32 {
+	c := t.nodes[i]
+	if c.cells&(1<<uint(p)) == 0 {
+		return i
+	}
+	mark := len(t.pending)
+	r := boundNode{label: c.label, cells: 1 << uint(c.label)}
+	for e := c.first; e < c.first+c.count; e++ {
+		n := t.edges[e]
+		// A path visits each cell once, so nothing below a choice of cell p is on cell p
+		if int(c.label) == p {
+			if set&(1<<uint(t.nodes[n].label)) == 0 {
+				continue
+			}
+		} else if n = t.restrictSum(n, p, set); n < 0 {
+			continue
+		}
+		bound, cells := t.child(n)
+		r.bound = max(r.bound, bound)
+		r.cells |= cells
+	}
+	if r.bound == 0 {
+		t.pending = t.pending[:mark]
+		return -1
+	}
+	return t.finish(r, mark)
+}
+
+// sumBounds finds, without building any restricted trees, the bound the tree would have if cell p held
+// only the i-th tile of set, for each i
+func (t *boundTree) sumBounds(i int32, p int, set uint64, out []int, depth int) {
+	n := &t.nodes[i]
+	if n.cells&(1<<uint(p)) == 0 {
+		for k := range out {
+			out[k] = int(n.bound)
+		}
+		return
+	}
+	for k := range out {
+		out[k] = int(n.points)
+	}
+	tmp := t.vector(depth, len(out))
+	for e := n.first; e < n.first+n.count; e++ {
+		t.choiceBounds(t.edges[e], p, set, tmp, depth+1)
+		for k := range out {
+			out[k] += tmp[k]
+		}
+	}
+}
+
+func (t *boundTree) choiceBounds(i int32, p int, set uint64, out []int, depth int) {
+	c := &t.nodes[i]
+	if c.cells&(1<<uint(p)) == 0 {
+		for k := range out {
+			out[k] = int(c.bound)
+		}
+		return
+	}
+	for k := range out {
+		out[k] = 0
+	}
+	if int(c.label) == p {
+		for e := c.first; e < c.first+c.count; e++ {
+			n := &t.nodes[t.edges[e]]
+			out[bits.OnesCount64(set&(1<<uint(n.label)-1))] = int(n.bound)
+		}
+		return
+	}
+	tmp := t.vector(depth, len(out))
+	for e := c.first; e < c.first+c.count; e++ {
+		t.sumBounds(t.edges[e], p, set, tmp, depth+1)
+		for k := range out {
+			out[k] = max(out[k], tmp[k])
+		}
+	}
+}
+
+// vector returns the scratch vector of bounds for a level of the tree
+func (t *boundTree) vector(depth, n int) []int {
+	for len(t.scratch) <= depth {
+		t.scratch = append(t.scratch, make([]int, maxBoundTiles))
+	}
+	return t.scratch[depth][:n]
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math/bits"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBoggleMaximize(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-common.txt")
+	cases := []struct {
+		rows, cols int
+		tiles      string
+	}{
+		{2, 3, "A E ST T"},
+		// Enough tiles that the buckets hold several, and cells must be split
+		{2, 2, "A E I O D L N QU R S T TH"},
+	}
+	for _, c := range cases {
+		tiles := strings.Fields(c.tiles)
+		bs, err := newSolver(c.rows, c.cols, GridTopology, dictfile, ClassicRule)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Brute force every board to find the true maximum
+		cells := make([]string, c.rows*c.cols)
+		expected := 0
+		var enumerate func(int)
+		enumerate = func(k int) {
+			if k == len(cells) {
+				grid := make([][]string, c.rows)
+				for r := range grid {
+					grid[r] = cells[r*c.cols : (r+1)*c.cols]
+				}
+				board, err := NewBoggleBoardArray(grid)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s := bs.score(board); s > expected {
+					expected = s
+				}
+				return
+			}
+			for _, l := range tiles {
+				cells[k] = l
+				enumerate(k + 1)
+			}
+		}
+		enumerate(0)
+
+		result := bs.maximize(tiles, 0, 2, nil)
+		if result.Board == nil {
+			t.Fatalf("%dx%d %v: no board found", c.rows, c.cols, tiles)
+		}
+		if result.Score != expected {
+			t.Errorf("%dx%d %v: score %d != expected %d", c.rows, c.cols, tiles, result.Score, expected)
+		}
+		if s := bs.score(result.Board); s != result.Score {
+			t.Errorf("%dx%d %v: board %s scores %d, reported %d", c.rows, c.cols, tiles, result.Board, s, result.Score)
+		}
+		// The board found must not depend on how the workers happen to be scheduled
+		if other := bs.maximize(tiles, 0, 3, nil); other.Board.String() != result.Board.String() {
+			t.Errorf("%dx%d %v: 3 workers found %s, 2 found %s", c.rows, c.cols, tiles, other.Board, result.Board)
+		}
+
+		result = bs.maximize(tiles, expected+1, 2, nil)
+		if result.Board != nil {
+			t.Errorf("%dx%d %v: found board %s with score %d above the maximum %d",
+				c.rows, c.cols, tiles, result.Board, result.Score, expected)
+		}
+	}
+}
+
+func TestBoundTreeRestrict(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-common.txt")
+	bs, err := newSolver(2, 2, GridTopology, dictfile, ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := boundSearch{solver: bs, tiles: strings.Fields("A E I O D L N QU R S T TH")}
+	all := uint64(1)<<uint(len(s.tiles)) - 1
+	sets := []uint64{all, all &^ 0xf0, 0x0f, 1<<7 | 1<<9}
+
+	// Restricting a cell of the tree must give the tree built from the restricted sets, and sumBounds
+	// must give the bound of each restriction without building it
+	for p, set := range sets {
+		s.tree.release(boundMark{})
+		root := s.build(sets)
+		bounds := make([]int, bits.OnesCount64(set))
+		s.tree.sumBounds(root, p, set, bounds, 0)
+		i := 0
+		for rest := set; rest != 0; rest &= rest - 1 {
+			tile := rest & -rest
+			restricted := s.tree.upper(s.tree.restrictSum(root, p, tile))
+			sets[p] = tile
+			built := s.tree.upper(s.build(sets))
+			sets[p] = set
+			if restricted != built || bounds[i] != built {
+				t.Errorf("cell %d tile %s: restricted bound %d, sumBounds %d, built %d",
+					p, s.tiles[bits.TrailingZeros64(tile)], restricted, bounds[i], built)
+			}
+			if built > s.tree.upper(root) {
+				t.Errorf("cell %d tile %s: bound %d above the unrestricted %d", p, s.tiles[bits.TrailingZeros64(tile)], built, s.tree.upper(root))
+			}
+			i++
+		}
+	}
+}
@@ -1,24 +1,18 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
-	"fmt"
-	"math/rand"
-	"os"
-	"path/filepath"
-	"runtime"
-	"strings"
-	"time"
+	"sync"
 )
 
-const minWordLength = 3
-
 type boggleSolver struct {
 	rows       int
 	cols       int
 	adjList    [][]int
-	dictionary OptimizedTrie
+	dictfile   string
+	dictionary *DAWG
+	rule       ScoringRule
+	// scorers recycles scratch space so that a solver can be shared by concurrent goroutines
+	scorers *sync.Pool
 }
 
 func buildAdjList(rows, cols int) [][]int {
@@ -48,124 +42,122 @@ func buildAdjList(rows, cols int) [][]int {
 	return ret
 }
 
-func buildScore(rows, cols int) []int {
-	score := make([]int, rows*cols+1)
-	for i := 0; i < rows*cols+1; i++ {
-		switch {
-		case i < minWordLength:
-			score[i] = 0
-		case i < 5:
-			score[i] = 1
-		case i == 5:
-			score[i] = 2
-		case i == 6:
-			score[i] = 3
-		case i == 7:
-			score[i] = 5
-		default:
-			score[i] = 11
-		}
-	}
-	return score
-}
-
-func newSolver(rows, cols int, dictfile string) (*boggleSolver, error) {
-	maxWordLength := rows * cols
-
-	adjList := buildAdjList(rows, cols)
-	score := buildScore(rows, cols)
-
-	file, err := os.Open(dictfile)
+func newSolver(rows, cols int, topology Topology, dictfile string, rule ScoringRule) (*boggleSolver, error) {
+	adjList, err := topology.AdjList(rows, cols)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	var dictionary OptimizedTrie
-	for scanner.Scan() {
-		w := scanner.Text()
-		if len(w) >= minWordLength && len(w) <= maxWordLength {
-			dictionary.Insert(strings.ToUpper(w), score[len(w)])
-		}
-	}
 
-	if err := scanner.Err(); err != nil {
+	words, err := loadDictionary(dictfile)
+	if err != nil {
 		return nil, err
 	}
+	// The dictionary is shared; only the scores of its words belong to this solver
+	dictionary := words.WithValues(rule.Score)
 
 	solver := boggleSolver{
 		rows:       rows,
 		cols:       cols,
 		adjList:    adjList,
+		dictfile:   dictfile,
 		dictionary: dictionary,
+		rule:       rule,
 	}
+	solver.scorers = &sync.Pool{New: func() interface{} { return newScorer(&solver) }}
 	return &solver, nil
 }
 
-func (bs *boggleSolver) score(bb Boggler) (int, *OptimizedTrie) {
-	visited := make([]bool, len(bs.adjList))
-	var results OptimizedTrie
-	var buf bytes.Buffer
-	score := 0
-
-	for p := 0; p < len(bs.adjList); p++ {
-		score += bs.dfs(bb, &bs.dictionary, p, &visited, &results, &buf)
-	}
+// score returns the total value of the distinct words on the board, borrowing a scorer from a pool.
+// The pool is emptied by garbage collection, and a new scorer allocates a stamp for every word in the
+// dictionary, so the optimizer's workers each own a scorer instead; the pool serves one-off calls.
+func (bs *boggleSolver) score(bb Boggler) int {
+	sc := bs.scorers.Get().(*scorer)
+	score := sc.score(bb)
+	bs.scorers.Put(sc)
+	return score
+}
 
-	return score, &results
+// maxKernelCells is the largest board whose visited cells fit in the scorer's bitmask
+const maxKernelCells = 64
+
+// scorer holds the scratch space needed to score boards with one solver.
+// A word is counted only once per board by stamping it with the generation of the board that found it,
+// so nothing needs to be cleared between boards.  A scorer is not safe for concurrent use.
+type scorer struct {
+	solver     *boggleSolver
+	cells      []string
+	seen       []uint32
+	generation uint32
 }
 
-func (bs *boggleSolver) dfs(bb Boggler, dictionary *OptimizedTrie, p int, visited *[]bool, results *OptimizedTrie, sb *bytes.Buffer) int {
+func newScorer(bs *boggleSolver) *scorer {
+	return &scorer{
+		solver: bs,
+		cells:  make([]string, len(bs.adjList)),
+		seen:   make([]uint32, bs.dictionary.Words()),
+	}
+}
 
-	if (*visited)[p] {
-		return 0
+// score returns the total value of the distinct words on the board.
+// Boards of up to 64 cells are scored without allocating; larger boards fall back to findWords.
+func (sc *scorer) score(bb Boggler) int {
+	if len(sc.solver.adjList) > maxKernelCells {
+		return sc.solver.findWords(bb, false).Score
+	}
+	sc.generation++
+	if sc.generation == 0 {
+		for i := range sc.seen {
+			sc.seen[i] = 0
+		}
+		sc.generation = 1
+	}
+	for p := range sc.cells {
+		sc.cells[p] = bb.GetLinear(p)
 	}
 
-	letter := bb.GetLinear(p)
 	score := 0
-
-	subtrie := dictionary.SubtrieR(letter)
-	if subtrie == nil {
-		return score
+	root := sc.solver.dictionary.Root()
+	for p := range sc.cells {
+		score += sc.dfs(root, p, 0)
 	}
+	return score
+}
 
-	(*visited)[p] = true
-	sb.WriteRune(letter)
-	if letter == 'Q' {
-		sb.WriteRune('U')
+func (sc *scorer) dfs(dictionary DAWGNode, p int, visited uint64) int {
+	// Blocked cells cannot be part of a word
+	tile := sc.cells[p]
+	if tile == "" {
+		return 0
 	}
-
-	score += subtrie.RootValue()
-	if score > 0 {
-		str := sb.String()
-		if !results.Has(str) {
-			results.Insert(str, score)
-		} else {
-			score = 0
-		}
+	next, ok := dictionary.Subtrie(tile)
+	if !ok {
+		return 0
 	}
 
-	for _, p2 := range bs.adjList[p] {
-		score += bs.dfs(bb, subtrie, p2, visited, results, sb)
+	score := 0
+	if next.word && sc.seen[next.index] != sc.generation {
+		sc.seen[next.index] = sc.generation
+		score = next.RootValue()
 	}
-
-	(*visited)[p] = false
-	sb.Truncate(sb.Len() - 1)
-	if letter == 'Q' {
-		sb.Truncate(sb.Len() - 1)
+	if next.first == 0 {
+		return score
 	}
 
+	visited |= 1 << uint(p)
+	for _, p2 := range sc.solver.adjList[p] {
+		if visited&(1<<uint(p2)) == 0 {
+			score += sc.dfs(next, p2, visited)
+		}
+	}
 	return score
 }
 
-func frequencyCount(dictfile string, maxWordLength int) ([][]float64, error) {
-	file, err := os.Open(dictfile)
+func frequencyCount(dictfile string, minWordLength int, maxWordLength int) ([][]float64, error) {
 	freqs := make([][]float64, 26)
+	dictionary, err := loadDictionary(dictfile)
 	if err != nil {
 		return freqs, err
 	}
-	defer file.Close()
 
 	sum := 0
 	counts := make([][]int, 26)
@@ -173,13 +165,10 @@ func frequencyCount(dictfile string, maxWordLength int) ([][]float64, error) {
 		counts[i] = make([]int, 26)
 	}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		w := scanner.Text()
+	dictionary.Each(func(_ int, w string) {
 		if len(w) < minWordLength || len(w) > maxWordLength {
-			continue
+			return
 		}
-		w = strings.ToUpper(w)
 		for l := 0; l < len(w)-1; l++ {
 			sum++
 			l1 := w[l] - 'A'
@@ -187,11 +176,7 @@ func frequencyCount(dictfile string, maxWordLength int) ([][]float64, error) {
 			counts[l1][l2]++
 			counts[l2][l1]++
 		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return freqs, err
-	}
+	})
 
 	for i, cnt := range counts {
 		freqs[i] = make([]float64, 26)
@@ -208,83 +193,14 @@ type boardScore struct {
 	board []string
 }
 
-func solve(dictfile string, best chan int, brd chan boardScore, flip chan bool) {
-	bs, err := newSolver(4, 4, dictfile)
-	if err != nil {
-		panic(err)
-	}
-
-	freqs, err := frequencyCount(dictfile, 16)
-	if err != nil {
-		panic(err)
-	}
-	board := newDiceBoard(4, 4, boggle1992)
-
-	topscore := 0
-	score := 0
-
-	for {
-		select {
-
-		case <-flip:
-			board = newDiceBoard(4, 4, boggle1992)
-
-		case topscore = <-best:
-			// Already did what I wanted to do...
-
-		default:
-			last := board.Clone()
-			lastScore := score
-
-			board.DictShuffle(bs.adjList, freqs)
-			score, _ = bs.score(board)
-
-			if score > topscore {
-				topscore = score
-				brd <- boardScore{score: topscore, board: board.ArrayLinear()}
-			}
-
-			if score <= lastScore && rand.Float64() > float64(score)/float64(lastScore) {
-				board = last.(*DiceBoard)
-				score = lastScore
-			}
-		}
-	}
-}
-
-func main() {
-
-	seed := time.Now().UnixNano()
-	rand.Seed(seed)
-	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
-
-	nprocs := runtime.GOMAXPROCS(0)
-	best := make([]chan int, nprocs)
-	brd := make(chan boardScore, nprocs)
-	flip := make(chan bool)
-
-	for i := 0; i < nprocs; i++ {
-		best[i] = make(chan int, 100)
-		go solve(dictfile, best[i], brd, flip)
-	}
-
-	i := 0
-	topscore := 0
-	start := int64(time.Nanosecond) * time.Now().UnixNano() / int64(time.Millisecond)
-
-	for {
-		select {
-		case b := <-brd:
-			if b.score > topscore {
-				topscore = b.score
-				fmt.Printf("%d,%d,%d,%s\n", i, int64(time.Nanosecond)*time.Now().UnixNano()/int64(time.Millisecond)-start, topscore, strings.Join(b.board, ","))
-				for _, bst := range best {
-					bst <- topscore
-				}
-			}
-		case <-time.After(time.Minute * 5):
-			flip <- true
-			i++
-		}
-	}
+// optimizeOptions configures the boards searched by the optimizer
+type optimizeOptions struct {
+	rows     int
+	cols     int
+	topology Topology
+	dice     []Die
+	dictfile string
+	rule     ScoringRule
+	// cacheSize is the number of board scores the optimizer remembers, or zero to remember none
+	cacheSize int
 }
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"math/rand"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -20,12 +27,12 @@ func TestBoggleSolver(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		bs, err := newSolver(board.rows, board.cols, dictfile)
+		bs, err := newSolver(board.rows, board.cols, GridTopology, dictfile, ClassicRule)
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		s, _ := bs.score(board)
+		s := bs.score(board)
 		//fmt.Printf("%s\n", board)
 		if s != pts {
 			t.Errorf("score %d != expected %d", s, pts)
@@ -33,16 +40,257 @@ func TestBoggleSolver(t *testing.T) {
 	}
 }
 
+// referenceSolver scores boards the way the solver did before the scoring kernel: a pointer trie,
+// a visited slice, and a second trie of the words found so far.  It is kept to check the kernel and
+// to measure its gain.
+type referenceSolver struct {
+	adjList    [][]int
+	dictionary OptimizedTrie
+}
+
+func newReferenceSolver(t testing.TB, adjList [][]int, dictfile string, rule ScoringRule) *referenceSolver {
+	words, err := readWordList(dictfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := &referenceSolver{adjList: adjList}
+	for _, w := range words {
+		if score := rule.Score(w); score > 0 {
+			rs.dictionary.Insert(w, score)
+		}
+	}
+	return rs
+}
+
+func (rs *referenceSolver) score(bb Boggler) int {
+	visited := make([]bool, len(rs.adjList))
+	var results OptimizedTrie
+	var buf bytes.Buffer
+	score := 0
+	for p := range rs.adjList {
+		score += rs.dfs(bb, &rs.dictionary, p, &visited, &results, &buf)
+	}
+	return score
+}
+
+func (rs *referenceSolver) dfs(bb Boggler, dictionary *OptimizedTrie, p int, visited *[]bool, results *OptimizedTrie, sb *bytes.Buffer) int {
+	letter := bb.GetLinear(p)
+	if (*visited)[p] || letter == "" {
+		return 0
+	}
+	subtrie := dictionary.Subtrie(letter)
+	if subtrie == nil {
+		return 0
+	}
+
+	(*visited)[p] = true
+	sb.WriteString(letter)
+
+	score := subtrie.RootValue()
+	if score > 0 {
+		str := sb.String()
+		if !results.Has(str) {
+			results.Insert(str, score)
+		} else {
+			score = 0
+		}
+	}
+	for _, p2 := range rs.adjList[p] {
+		score += rs.dfs(bb, subtrie, p2, visited, results, sb)
+	}
+
+	(*visited)[p] = false
+	sb.Truncate(sb.Len() - len(letter))
+	return score
+}
+
+func TestBoggleSolverKernel(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
+	rng := rand.New(rand.NewSource(2))
+	for _, size := range [][2]int{{4, 4}, {5, 5}, {8, 8}} {
+		rows, cols := size[0], size[1]
+		bs, err := newSolver(rows, cols, GridTopology, dictfile, ClassicRule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs := newReferenceSolver(t, bs.adjList, dictfile, ClassicRule)
+		for i := 0; i < 20; i++ {
+			board := NewBoggleBoardRandom(rng, rows, cols)
+			if s, expected := bs.score(board), rs.score(board); s != expected {
+				t.Errorf("%d-by-%d board scored %d, expected %d:\n%s", rows, cols, s, expected, board)
+			}
+		}
+	}
+
+	// Boards too large for the bitmask fall back to findWords
+	bs, err := newSolver(9, 9, GridTopology, dictfile, ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := NewBoggleBoardRandom(rng, 9, 9)
+	if s, expected := bs.score(board), newReferenceSolver(t, bs.adjList, dictfile, ClassicRule).score(board); s != expected {
+		t.Errorf("9-by-9 board scored %d, expected %d", s, expected)
+	}
+}
+
+func TestBoggleSolverAllocations(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
+	bs, err := newSolver(4, 4, GridTopology, dictfile, ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := newDiceBoard(rand.New(rand.NewSource(1)), 4, 4, diceSets["1992"])
+	bs.score(board)
+	if allocs := testing.AllocsPerRun(100, func() { bs.score(board) }); allocs != 0 {
+		t.Errorf("scoring a board made %.1f allocations, expected none", allocs)
+	}
+
+	// Garbage collection empties the solver's pool, but not a scorer owned by its caller
+	sc := newScorer(bs)
+	if allocs := testing.AllocsPerRun(20, func() {
+		runtime.GC()
+		sc.score(board)
+	}); allocs != 0 {
+		t.Errorf("scoring a board with its own scorer made %.1f allocations, expected none", allocs)
+	}
+}
+
 func BenchmarkBoggleSolver(b *testing.B) {
 	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
-	bs, err := newSolver(4, 4, dictfile)
+	bs, err := newSolver(4, 4, GridTopology, dictfile, ClassicRule)
 	if err != nil {
 		b.Fatal(err)
 	}
-	board := newDiceBoard(4, 4, boggle1992)
+	rng := rand.New(rand.NewSource(1))
+	board := newDiceBoard(rng, 4, 4, diceSets["1992"])
+	b.ReportAllocs()
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
 		bs.score(board)
 	}
 }
+
+func BenchmarkBoggleSolverOwnScorer(b *testing.B) {
+	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
+	bs, err := newSolver(4, 4, GridTopology, dictfile, ClassicRule)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sc := newScorer(bs)
+	rng := rand.New(rand.NewSource(1))
+	board := newDiceBoard(rng, 4, 4, diceSets["1992"])
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sc.score(board)
+	}
+}
+
+func BenchmarkBoggleSolverReference(b *testing.B) {
+	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
+	rs := newReferenceSolver(b, buildAdjList(4, 4), dictfile, ClassicRule)
+	rng := rand.New(rand.NewSource(1))
+	board := newDiceBoard(rng, 4, 4, diceSets["1992"])
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		rs.score(board)
+	}
+}
+
+func BenchmarkBoggleSolverParallel(b *testing.B) {
+	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
+	bs, err := newSolver(4, 4, GridTopology, dictfile, ClassicRule)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var seed int64
+	var mu sync.Mutex
+	b.RunParallel(func(pb *testing.PB) {
+		mu.Lock()
+		seed++
+		rng := rand.New(rand.NewSource(seed))
+		mu.Unlock()
+		board := newDiceBoard(rng, 4, 4, diceSets["1992"])
+		for pb.Next() {
+			bs.score(board)
+		}
+	})
+}
+
+func TestBoggleSolverPaths(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-yawl.txt")
+
+	for _, name := range []string{"board-q.txt", "board-points1000.txt", "board-dodo.txt"} {
+		board, err := ReadBoggleBoard(filepath.Join("test", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bs, err := newSolver(board.rows, board.cols, GridTopology, dictfile, ClassicRule)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := bs.score(board)
+		sol := bs.findWords(board, true)
+		if sol.Score != expected {
+			t.Errorf("%s: score %d != expected %d", name, sol.Score, expected)
+		}
+
+		for _, w := range sol.Words {
+			if len(w.Paths) == 0 || !reflect.DeepEqual(w.Path, w.Paths[0]) {
+				t.Errorf("%s: word %s path %v not first of all paths %v", name, w.Word, w.Path, w.Paths)
+			}
+			for _, path := range w.Paths {
+				spelled := ""
+				for i, c := range path {
+					if i > 0 {
+						dr, dc := c.Row-path[i-1].Row, c.Col-path[i-1].Col
+						if dr < -1 || dr > 1 || dc < -1 || dc > 1 {
+							t.Errorf("%s: word %s path %v is not connected", name, w.Word, path)
+						}
+					}
+					spelled += board.ArrayLinear()[c.Row*board.cols+c.Col]
+				}
+				if strings.ToUpper(spelled) != w.Word {
+					t.Errorf("%s: path %v spells %s, not %s", name, path, spelled, w.Word)
+				}
+			}
+		}
+	}
+}
+
+func TestBoggleSolverMultiLetter(t *testing.T) {
+	dictfile := filepath.Join(t.TempDir(), "dictionary.txt")
+	if err := ioutil.WriteFile(dictfile, []byte("the\nthen\nhen\neth\nnth\nten\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var board BoggleBoard
+	if err := board.UnmarshalText([]byte("2 2\nTh E\n. n")); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(board.rows, board.cols, GridTopology, dictfile, ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sol := bs.findWords(&board, false)
+	words := make([]string, len(sol.Words))
+	for i, w := range sol.Words {
+		words[i] = w.Word
+	}
+	expected := []string{"ETH", "NTH", "THE", "THEN"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("found words %v, expected %v", words, expected)
+	}
+	if s := bs.score(&board); s != len(expected) {
+		t.Errorf("score %d != expected %d", s, len(expected))
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// splitMix is a SplitMix64 random source.
+// Its entire state is a single integer, so a generator can be saved to a checkpoint and restored exactly.
+type splitMix struct {
+	state uint64
+}
+
+func newSplitMix(seed int64) *splitMix {
+	return &splitMix{state: uint64(seed)}
+}
+
+// newSplitMixRand returns a generator drawing from a new SplitMix64 source along with the source itself
+func newSplitMixRand(seed int64) (*rand.Rand, *splitMix) {
+	src := newSplitMix(seed)
+	return rand.New(src), src
+}
+
+// Seed implements rand.Source's interface
+func (s *splitMix) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Uint64 implements rand.Source64's interface
+func (s *splitMix) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 implements rand.Source's interface
+func (s *splitMix) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// diceBoardState is the saved form of a DiceBoard: which die sits in each cell and which face is showing
+type diceBoardState struct {
+	Die  [][]int `json:"die"`
+	Face [][]int `json:"face"`
+}
+
+func (bb *DiceBoard) state() diceBoardState {
+	c := bb.Clone().(*DiceBoard)
+	return diceBoardState{Die: c.die, Face: c.face}
+}
+
+// restore rebuilds a board from its saved state, checking that it is consistent with the dice
+func (s diceBoardState) restore(rows int, cols int, dice []Die) (*DiceBoard, error) {
+	if len(s.Die) != rows || len(s.Face) != rows {
+		return nil, fmt.Errorf("saved board does not have %d rows", rows)
+	}
+	used := make([]bool, len(dice))
+	for i := 0; i < rows; i++ {
+		if len(s.Die[i]) != cols || len(s.Face[i]) != cols {
+			return nil, fmt.Errorf("saved board does not have %d columns", cols)
+		}
+		for j := 0; j < cols; j++ {
+			d := s.Die[i][j]
+			if d < 0 || d >= len(dice) || used[d] {
+				return nil, fmt.Errorf("saved board has invalid die %d", d)
+			}
+			used[d] = true
+			if f := s.Face[i][j]; f < 0 || f >= len(dice[d]) {
+				return nil, fmt.Errorf("saved board has invalid face %d of die %d", f, d)
+			}
+		}
+	}
+	bb := &DiceBoard{rows: rows, cols: cols, dice: dice, die: s.Die, face: s.Face}
+	return bb.Clone().(*DiceBoard), nil
+}
+
+// workerState is the saved state of one optimizer worker or tempering replica
+type workerState struct {
+	Board diceBoardState `json:"board"`
+	Score int            `json:"score"`
+	RNG   uint64         `json:"rng"`
+
+	// Temperature, Moves, and Accepted describe a tempering replica
+	Temperature float64 `json:"temperature,omitempty"`
+	Moves       int     `json:"moves,omitempty"`
+	Accepted    int     `json:"accepted,omitempty"`
+}
+
+// checkpoint is the saved state of an optimization run
+type checkpoint struct {
+	// Flags are the command-line settings that describe the search, restored on resume
+	Flags map[string]string `json:"flags"`
+	// Dice are saved so that runs using a dice file can resume without it
+	Dice []Die `json:"dice"`
+
+	// Round counts restarts (restart method), rounds (tempering method), or generations (genetic method)
+	Round int `json:"round"`
+	// Steps counts the steps each worker has taken since the last restart (restart method)
+	Steps     int           `json:"steps,omitempty"`
+	Elapsed   time.Duration `json:"elapsed"`
+	RNG       uint64        `json:"rng"`
+	Best      int           `json:"best"`
+	BestBoard []string      `json:"best_board"`
+
+	Workers []workerState `json:"workers"`
+
+	// Swaps and SwapAccepted count exchanges between each temperature and the next (tempering method)
+	Swaps        []int `json:"swaps,omitempty"`
+	SwapAccepted []int `json:"swap_accepted,omitempty"`
+}
+
+// readCheckpoint reads a checkpoint written by writeCheckpoint
+func readCheckpoint(filename string) (*checkpoint, error) {
+	bs, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(bs, &cp); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(cp.Workers) == 0 {
+		return nil, fmt.Errorf("%s: checkpoint has no workers", filename)
+	}
+	return &cp, nil
+}
+
+// writeCheckpoint saves a checkpoint, replacing the file atomically so that a crash while writing
+// never destroys the previous checkpoint
+func writeCheckpoint(filename string, cp *checkpoint) error {
+	bs, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(bs); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// checkpointer periodically saves the state of an optimization run
+type checkpointer struct {
+	filename string
+	flags    map[string]string
+	dice     []Die
+	// tick fires when a checkpoint is due; it is nil if checkpointing is disabled
+	tick <-chan time.Time
+}
+
+// save fills in the run settings and writes the checkpoint, if checkpointing is enabled
+func (c *checkpointer) save(cp *checkpoint) error {
+	if c == nil || c.filename == "" {
+		return nil
+	}
+	cp.Flags = c.flags
+	cp.Dice = c.dice
+	return writeCheckpoint(c.filename, cp)
+}
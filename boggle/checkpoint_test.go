@@ -0,0 +1,87 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitMixRestore(t *testing.T) {
+	rng, src := newSplitMixRand(42)
+	for i := 0; i < 10; i++ {
+		rng.Intn(100)
+	}
+	saved := src.state
+	var first [5]int64
+	for i := range first {
+		first[i] = rng.Int63()
+	}
+
+	restored, _ := newSplitMixRand(0)
+	restored.Seed(int64(saved))
+	for i, e := range first {
+		if v := restored.Int63(); v != e {
+			t.Errorf("value %d after restore = %d, expected %d", i, v, e)
+		}
+	}
+}
+
+func TestTemperingCheckpoint(t *testing.T) {
+	opts, bs, freqs := testOptimizer(t)
+
+	uninterrupted := newTempering(1234, bs, freqs, opts, []float64{1, 10, 100}, 10)
+	interrupted := newTempering(1234, bs, freqs, opts, []float64{1, 10, 100}, 10)
+	for r := 0; r < 3; r++ {
+		uninterrupted.round()
+		interrupted.round()
+	}
+
+	filename := filepath.Join(t.TempDir(), "checkpoint.json")
+	saver := &checkpointer{filename: filename, flags: map[string]string{"rows": "4"}, dice: opts.dice}
+	cp := interrupted.checkpoint()
+	cp.Round = 3
+	if err := saver.save(cp); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := readCheckpoint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Round != 3 || loaded.Flags["rows"] != "4" || !reflect.DeepEqual(loaded.Dice, opts.dice) {
+		t.Errorf("checkpoint settings were not saved: %+v", loaded)
+	}
+	resumed, err := restoreTempering(loaded, bs, freqs, opts, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for r := 0; r < 3; r++ {
+		uninterrupted.round()
+		resumed.round()
+	}
+	for i, r := range uninterrupted.replicas {
+		if got, expected := resumed.replicas[i].board.ArrayLinear(), r.board.ArrayLinear(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("resumed replica %d ended on %v, expected %v", i, got, expected)
+		}
+	}
+	if resumed.best != uninterrupted.best {
+		t.Errorf("resumed best %d, expected %d", resumed.best, uninterrupted.best)
+	}
+}
+
+func TestRestoreBoardValidation(t *testing.T) {
+	dice := diceSets["1992"]
+	rng, _ := newSplitMixRand(5)
+	bb := newDiceBoard(rng, 4, 4, dice)
+	state := bb.state()
+	if _, err := state.restore(4, 4, dice); err != nil {
+		t.Error(err)
+	}
+	if _, err := state.restore(4, 5, dice); err == nil {
+		t.Error("restored a 4-by-4 board as 4-by-5")
+	}
+	state.Die[0][0] = state.Die[0][1]
+	if _, err := state.restore(4, 4, dice); err == nil {
+		t.Error("restored a board using the same die twice")
+	}
+}
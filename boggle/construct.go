@@ -0,0 +1,328 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// fillerLetters fill the cells that no placed word uses, when there are no dice to roll for them
+const fillerLetters = "ETAOINSRHL"
+
+// constructSearch places words on a board by backtracking.  Words are placed longest first, each along a path
+// of distinct adjacent cells that either already hold the right tile or are empty.  Cells already holding the
+// right tile are tried first, so words share as many cells as they can.  Leaving a word off the board is tried
+// last, and branches that cannot place more words than the best board found so far are pruned, so when the
+// search finishes the best board holds the largest set of words that fit.
+type constructSearch struct {
+	adjList [][]int
+	tiles   []string
+	// dice, if not nil, must be able to roll the board
+	dice  []Die
+	words []string
+	cells []string
+	// placed are the paths of the words on the board, or nil for words left off
+	placed [][]int
+	limit  int
+	nodes  int
+
+	best      int
+	bestCells []string
+	bestPaths [][]int
+}
+
+// constructResult is the outcome of a search for a board holding a list of words
+type constructResult struct {
+	Board *BoggleBoard
+	// Paths gives the path of each word placed on the board; words not placed are missing
+	Paths map[string][]Cell
+	// Missing lists the words that are not on the board
+	Missing []string
+	// Exhausted is true if the search finished, so that no board holds more of the words
+	Exhausted bool
+	Nodes     int
+}
+
+// construct searches for a board on which every word can be traced, building each cell from the tiles given.
+// If dice are given, only tiles on the dice are used and the board must be one the dice can roll.
+// The search stops after visiting limit partial boards, keeping the board holding the most words so far.
+func construct(rows, cols int, adjList [][]int, words []string, tiles []string, dice []Die, limit int) constructResult {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			unique = append(unique, w)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool { return len(unique[i]) > len(unique[j]) })
+
+	if dice != nil {
+		faces := make(map[string]bool)
+		tiles = nil
+		for _, d := range dice {
+			for _, f := range d {
+				if f != "" && !faces[f] {
+					faces[f] = true
+					tiles = append(tiles, f)
+				}
+			}
+		}
+	}
+
+	s := &constructSearch{
+		adjList: adjList,
+		tiles:   tiles,
+		dice:    dice,
+		words:   unique,
+		cells:   make([]string, rows*cols),
+		placed:  make([][]int, len(unique)),
+		limit:   limit,
+		best:    -1,
+		// Leaving every word off is the board found if the search gives up before reaching any other
+		bestCells: make([]string, rows*cols),
+		bestPaths: make([][]int, len(unique)),
+	}
+	s.search(0, 0)
+
+	result := constructResult{Paths: make(map[string][]Cell), Exhausted: s.nodes <= limit, Nodes: s.nodes}
+	for i, w := range s.words {
+		if s.bestPaths[i] == nil {
+			result.Missing = append(result.Missing, w)
+			continue
+		}
+		path := make([]Cell, len(s.bestPaths[i]))
+		for k, p := range s.bestPaths[i] {
+			path[k] = Cell{Row: p / cols, Col: p % cols}
+		}
+		result.Paths[w] = path
+	}
+	result.Board = s.fill(rows, cols)
+	return result
+}
+
+// search places words k onward, with placed of the words before k on the board
+func (s *constructSearch) search(k int, placed int) {
+	if s.nodes > s.limit || placed+len(s.words)-k <= s.best {
+		return
+	}
+	if k == len(s.words) {
+		s.best = placed
+		s.bestCells = append(s.bestCells[:0], s.cells...)
+		s.bestPaths = make([][]int, len(s.placed))
+		for i, p := range s.placed {
+			if p != nil {
+				s.bestPaths[i] = append([]int(nil), p...)
+			}
+		}
+		return
+	}
+
+	s.trace(s.words[k], -1, nil, func(path []int) {
+		s.placed[k] = path
+		s.search(k+1, placed+1)
+		s.placed[k] = nil
+	})
+	s.search(k+1, placed)
+}
+
+// trace calls found with each path spelling the rest of a word that continues from cell prev (or starts anywhere
+// if prev is -1), filling empty cells along the way.  The cells are restored before it returns.
+func (s *constructSearch) trace(rest string, prev int, path []int, found func(path []int)) {
+	s.nodes++
+	if rest == "" {
+		found(path)
+		return
+	}
+	var next []int
+	if prev < 0 {
+		next = make([]int, len(s.cells))
+		for i := range next {
+			next[i] = i
+		}
+	} else {
+		next = s.adjList[prev]
+	}
+
+	// Reuse cells holding the right tile before filling empty ones
+	for _, empty := range []bool{false, true} {
+		for _, p := range next {
+			if s.done() || (s.cells[p] == "") != empty || onPath(path, p) {
+				continue
+			}
+			if !empty {
+				if strings.HasPrefix(rest, s.cells[p]) {
+					s.trace(rest[len(s.cells[p]):], p, append(path, p), found)
+				}
+				continue
+			}
+			for _, t := range s.tiles {
+				if !strings.HasPrefix(rest, t) {
+					continue
+				}
+				s.cells[p] = t
+				if s.dice == nil || s.rollable() {
+					s.trace(rest[len(t):], p, append(path, p), found)
+				}
+				s.cells[p] = ""
+			}
+		}
+	}
+}
+
+// done reports whether the search should stop, because it is out of nodes or every word is on the best board
+func (s *constructSearch) done() bool {
+	return s.nodes > s.limit || s.best == len(s.words)
+}
+
+// rollable reports whether the dice can show the tiles placed so far
+func (s *constructSearch) rollable() bool {
+	_, missing := matchDice(s.cells, s.dice, true)
+	return missing < 0
+}
+
+func onPath(path []int, p int) bool {
+	for _, q := range path {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// fill completes the best board found, giving the cells no word uses a face of a leftover die or a filler letter
+func (s *constructSearch) fill(rows, cols int) *BoggleBoard {
+	cells := append([]string(nil), s.bestCells...)
+	if s.dice != nil {
+		dieOf, _ := matchDice(cells, s.dice, true)
+		used := make([]bool, len(s.dice))
+		for _, d := range dieOf {
+			if d >= 0 {
+				used[d] = true
+			}
+		}
+		d := 0
+		for c := range cells {
+			if dieOf[c] >= 0 {
+				continue
+			}
+			for used[d] {
+				d++
+			}
+			used[d] = true
+			cells[c] = s.dice[d][0]
+			for _, f := range s.dice[d] {
+				if f != "" {
+					cells[c] = f
+					break
+				}
+			}
+		}
+	} else {
+		f := 0
+		for c := range cells {
+			if cells[c] == "" {
+				cells[c] = fillerLetters[f%len(fillerLetters) : f%len(fillerLetters)+1]
+				f++
+			}
+		}
+	}
+
+	board := make([][]string, rows)
+	for i := range board {
+		board[i] = cells[i*cols : (i+1)*cols]
+	}
+	return &BoggleBoard{rows: rows, cols: cols, board: board}
+}
+
+func runConstruct(args []string) error {
+	fs := flag.NewFlagSet("construct", flag.ExitOnError)
+	rows := fs.Int("rows", 4, "number of rows on the board")
+	cols := fs.Int("cols", 4, "number of columns on the board")
+	diceName := fs.String("dice", "", "dice set the board must be rollable with (1992, 1983, master, big, or a dice file)")
+	tileList := fs.String("tiles", alphabet, "tiles that may be placed on the board when no dice are given")
+	wordFile := fs.String("words", "", "file listing the words to place, one per line")
+	limit := fs.Int("limit", 10000000, "most partial boards to search")
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file used to score the board")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: construct [flags] [word...]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	words := fs.Args()
+	if *wordFile != "" {
+		list, err := readWordList(*wordFile)
+		if err != nil {
+			return err
+		}
+		words = append(words, list...)
+	}
+	seen := make(map[string]bool)
+	var unique []string
+	for _, w := range words {
+		word, _, _, reason := normalizeWord(w, defaultWordListOptions)
+		if reason != keepWord || word == "" {
+			return fmt.Errorf("%q is not a word made of letters", w)
+		}
+		if !seen[word] {
+			seen[word] = true
+			unique = append(unique, word)
+		}
+	}
+	words = unique
+	if len(words) == 0 {
+		fs.Usage()
+		return errors.New("construct requires words to place")
+	}
+
+	var dice []Die
+	if *diceName != "" {
+		var err error
+		if dice, err = LoadDice(*diceName); err != nil {
+			return err
+		}
+		if err := ValidateDice(dice, *rows, *cols); err != nil {
+			return fmt.Errorf("dice set %s: %v", *diceName, err)
+		}
+	}
+	tiles, err := ParseDie(*tileList)
+	if err != nil {
+		return err
+	}
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	bs, err := newSolver(*rows, *cols, topo, *dictfile, r)
+	if err != nil {
+		return err
+	}
+
+	result := construct(*rows, *cols, bs.adjList, words, tiles, dice, *limit)
+	fmt.Printf("%s\n", result.Board)
+	for _, w := range words {
+		if path, ok := result.Paths[w]; ok {
+			fmt.Printf("%s: %s\n", w, formatPath(path))
+		}
+	}
+	fmt.Printf("score: %d\n", bs.score(result.Board))
+	if len(result.Missing) == 0 {
+		return nil
+	}
+	if result.Exhausted {
+		fmt.Fprintf(os.Stderr, "no board holds every word; the most that fit leave out %s\n", strings.Join(result.Missing, " "))
+	} else {
+		fmt.Fprintf(os.Stderr, "gave up after %d partial boards, leaving out %s\n", result.Nodes, strings.Join(result.Missing, " "))
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConstruct(t *testing.T) {
+	tiles, err := ParseDie(alphabet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(3, 3, GridTopology, filepath.Join("dictionaries", "dictionary-common.txt"), ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := []string{"QUIT", "TIGER", "GRIT", "TRIO", "ROT", "QUIT"}
+	result := construct(3, 3, bs.adjList, words, tiles, nil, 1000000)
+	if len(result.Missing) != 0 || !result.Exhausted {
+		t.Fatalf("missing %v from\n%s", result.Missing, result.Board)
+	}
+	for _, w := range words {
+		if bs.findPath(result.Board, w) == nil {
+			t.Errorf("%s is not on\n%s", w, result.Board)
+		}
+		if len(result.Paths[w]) == 0 {
+			t.Errorf("no path reported for %s", w)
+		}
+	}
+
+	// A word longer than the board has cells can never fit
+	bs, err = newSolver(2, 2, GridTopology, filepath.Join("dictionaries", "dictionary-common.txt"), ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = construct(2, 2, bs.adjList, []string{"STONE", "AT", "ON", "TO"}, tiles, nil, 1000000)
+	if !result.Exhausted || !reflect.DeepEqual(result.Missing, []string{"STONE"}) {
+		t.Errorf("missing %v from\n%s", result.Missing, result.Board)
+	}
+	if len(result.Paths) != 3 {
+		t.Errorf("placed %v", result.Paths)
+	}
+}
+
+func TestConstructDice(t *testing.T) {
+	dice := diceSets["1992"]
+	bs, err := newSolver(4, 4, GridTopology, filepath.Join("dictionaries", "dictionary-common.txt"), ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := []string{"QUIET", "STONE", "WHEEL"}
+	result := construct(4, 4, bs.adjList, words, nil, dice, 1000000)
+	if len(result.Missing) != 0 {
+		t.Fatalf("missing %v from\n%s", result.Missing, result.Board)
+	}
+	for _, w := range words {
+		if bs.findPath(result.Board, w) == nil {
+			t.Errorf("%s is not on\n%s", w, result.Board)
+		}
+	}
+	if _, err := MatchDice(result.Board, dice); err != nil {
+		t.Errorf("constructed board cannot be rolled: %v\n%s", err, result.Board)
+	}
+
+	// The 1992 dice have a single X
+	result = construct(4, 4, bs.adjList, []string{"AXE", "OX", "EX"}, nil, dice, 1000000)
+	if !result.Exhausted || len(result.Missing) != 0 {
+		t.Errorf("missing %v from\n%s", result.Missing, result.Board)
+	}
+	result = construct(4, 4, bs.adjList, []string{"AXE", "XXX"}, nil, dice, 1000000)
+	if !result.Exhausted || !reflect.DeepEqual(result.Missing, []string{"XXX"}) {
+		t.Errorf("missing %v from\n%s", result.Missing, result.Board)
+	}
+}
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A DAWG (directed acyclic word graph) is a minimized, immutable trie in which every set of identical
+// suffixes is stored once.  It is held as a flat array of edges, so a dictionary can be shared
+// read-only by every solver and written to disk and memory-mapped instead of rebuilt.
+//
+// Each node is a run of consecutive edges, one per child, sorted by letter.  An edge packs the child's
+// letter, a flag marking the last edge of the run, a flag marking the child as the end of a word, and
+// the index of the child's first edge.  Edge zero is never used, so a child with no children of its own
+// has index zero.
+//
+// Words are numbered from zero in sorted order.  Alongside each edge is the number of words that
+// start with the letters of the earlier edges of the same run, which is enough to compute the number
+// of any word while walking down to it.  Per-word data like scores is kept in arrays indexed by word number.
+type DAWG struct {
+	edges   []uint32
+	offsets []uint32
+	root    uint32
+	words   int
+
+	// values holds the value of each word, or is nil if every word has the value one
+	values []int32
+
+	// data is the memory mapping backing edges and offsets, if any
+	data []byte
+}
+
+const (
+	edgeLetterMask = 0x1f
+	edgeLast       = 1 << 5
+	edgeTerminal   = 1 << 6
+	edgeTargetBits = 7
+
+	// maxEdges is the largest number of edges whose indices fit in an edge
+	maxEdges = 1 << (32 - edgeTargetBits)
+
+	// gaddagSeparator marks the end of the reversed prefix of a GADDAG entry; it sorts after 'Z'
+	gaddagSeparator = 'Z' + 1
+)
+
+// dawgMagic begins every DAWG file
+var dawgMagic = []byte("BOGDAWG\x01")
+
+// dawgHeaderSize is the length of the magic number and the edge count, root, and word count that follow it
+const dawgHeaderSize = 20
+
+// DAWGNode is a position in a DAWG: the prefix spelled by the letters followed from the root
+type DAWGNode struct {
+	d *DAWG
+	// first is the index of the node's first edge, or zero if it has no children
+	first uint32
+	word  bool
+	// index is the number of the word spelled by the prefix, or the number of words before it if it is not a word
+	index uint32
+}
+
+// Root returns the node for the empty prefix
+func (d *DAWG) Root() DAWGNode {
+	return DAWGNode{d: d, first: d.root}
+}
+
+// Words returns the number of words in the DAWG
+func (d *DAWG) Words() int {
+	return d.words
+}
+
+// Get returns the value associated with the given word, or zero if it is not in the DAWG
+func (d *DAWG) Get(key string) int {
+	n, ok := d.Root().Subtrie(key)
+	if !ok {
+		return 0
+	}
+	return n.RootValue()
+}
+
+// Has returns true if the word is in the DAWG
+func (d *DAWG) Has(key string) bool {
+	n, ok := d.Root().Subtrie(key)
+	return ok && n.word
+}
+
+// WordID returns the number of the given word, or -1 if it is not in the DAWG
+func (d *DAWG) WordID(key string) int {
+	n, ok := d.Root().Subtrie(key)
+	if !ok {
+		return -1
+	}
+	return n.WordID()
+}
+
+// Value returns the value of the word with the given number
+func (d *DAWG) Value(id int) int {
+	if d.values == nil {
+		return 1
+	}
+	return int(d.values[id])
+}
+
+// Subtrie returns the node reached by following the letters of key, and false if there is no such node
+func (n DAWGNode) Subtrie(key string) (DAWGNode, bool) {
+	for i := 0; i < len(key); i++ {
+		var ok bool
+		if n, ok = n.child(key[i]); !ok {
+			return n, false
+		}
+	}
+	return n, true
+}
+
+// SubtrieR returns the node reached by following a single letter, and false if there is no such node.
+// A 'Q' is followed by a 'U', matching the printing on the dice.
+func (n DAWGNode) SubtrieR(key rune) (DAWGNode, bool) {
+	if key == 'Q' {
+		return n.Subtrie("QU")
+	}
+	if key < 'A' || key > 'Z' {
+		return n, false
+	}
+	return n.child(byte(key))
+}
+
+func (n DAWGNode) child(c byte) (DAWGNode, bool) {
+	if n.first == 0 {
+		return n, false
+	}
+	letter := uint32(c - 'A')
+	edges := n.d.edges
+	for e := n.first; ; e++ {
+		edge := edges[e]
+		l := edge & edgeLetterMask
+		if l == letter {
+			index := n.index + n.d.offsets[e]
+			if n.word {
+				index++
+			}
+			return DAWGNode{d: n.d, first: edge >> edgeTargetBits, word: edge&edgeTerminal != 0, index: index}, true
+		}
+		if l > letter || edge&edgeLast != 0 {
+			return n, false
+		}
+	}
+}
+
+// Separator returns the node reached by following the separator of a GADDAG entry, and false if there is no such node
+func (n DAWGNode) Separator() (DAWGNode, bool) {
+	return n.child(gaddagSeparator)
+}
+
+// RootValue returns the value of the word spelled by the node's prefix, or zero if it is not a word
+func (n DAWGNode) RootValue() int {
+	if !n.word {
+		return 0
+	}
+	if n.d.values == nil {
+		return 1
+	}
+	return int(n.d.values[n.index])
+}
+
+// IsWord returns true if the node's prefix is a word
+func (n DAWGNode) IsWord() bool {
+	return n.word
+}
+
+// WordID returns the number of the word spelled by the node's prefix, or -1 if it is not a word
+func (n DAWGNode) WordID() int {
+	if !n.word {
+		return -1
+	}
+	return int(n.index)
+}
+
+// Leaf returns true if no word continues past the node's prefix
+func (n DAWGNode) Leaf() bool {
+	return n.first == 0
+}
+
+// Each visits every word in the DAWG in order along with its number
+func (d *DAWG) Each(visitor func(id int, word string)) {
+	var buf []byte
+	id := 0
+	var walk func(first uint32)
+	walk = func(first uint32) {
+		if first == 0 {
+			return
+		}
+		for e := first; ; e++ {
+			edge := d.edges[e]
+			buf = append(buf, byte('A'+edge&edgeLetterMask))
+			if edge&edgeTerminal != 0 {
+				visitor(id, string(buf))
+				id++
+			}
+			walk(edge >> edgeTargetBits)
+			buf = buf[:len(buf)-1]
+			if edge&edgeLast != 0 {
+				return
+			}
+		}
+	}
+	walk(d.root)
+}
+
+// WithValues returns a DAWG sharing this one's structure, with the value of each word given by value.
+// Words valued at zero remain in the DAWG but are reported as worth nothing by RootValue and Get.
+func (d *DAWG) WithValues(value func(word string) int) *DAWG {
+	values := make([]int32, d.words)
+	d.Each(func(id int, word string) {
+		values[id] = int32(value(word))
+	})
+	v := *d
+	v.values = values
+	return &v
+}
+
+// dawgBuilder incrementally builds a minimized DAWG from words added in sorted order (Daciuk et al., 2000).
+// Nodes whose words are all known are replaced by an equivalent registered node, if there is one.
+type dawgBuilder struct {
+	root     *buildNode
+	register map[string]*buildNode
+	// unchecked are the nodes along the last word added that have not been minimized yet
+	unchecked []*buildNode
+	last      string
+	words     int
+	nodes     int
+}
+
+type buildNode struct {
+	id       int
+	terminal bool
+	letters  []byte
+	children []*buildNode
+}
+
+func newDAWGBuilder() *dawgBuilder {
+	b := &dawgBuilder{root: &buildNode{}, register: make(map[string]*buildNode)}
+	b.nodes = 1
+	return b
+}
+
+// signature identifies a node by its terminal flag and its letters and registered children
+func (n *buildNode) signature() string {
+	var buf bytes.Buffer
+	if n.terminal {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	var id [4]byte
+	for i, c := range n.children {
+		buf.WriteByte(n.letters[i])
+		binary.LittleEndian.PutUint32(id[:], uint32(c.id))
+		buf.Write(id[:])
+	}
+	return buf.String()
+}
+
+func (b *dawgBuilder) add(word string) error {
+	if word == "" {
+		return errors.New("empty word")
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'A' || word[i] > gaddagSeparator {
+			return fmt.Errorf("invalid character %q in %q", word[i], word)
+		}
+	}
+	if b.words > 0 && word <= b.last {
+		return fmt.Errorf("word %q is not after %q", word, b.last)
+	}
+
+	common := 0
+	for common < len(word) && common < len(b.last) && word[common] == b.last[common] {
+		common++
+	}
+	b.minimize(common)
+
+	n := b.root
+	if len(b.unchecked) > 0 {
+		n = b.unchecked[len(b.unchecked)-1]
+	}
+	for i := common; i < len(word); i++ {
+		child := &buildNode{}
+		n.letters = append(n.letters, word[i])
+		n.children = append(n.children, child)
+		b.unchecked = append(b.unchecked, child)
+		n = child
+	}
+	n.terminal = true
+	b.last = word
+	b.words++
+	return nil
+}
+
+// minimize replaces the unchecked nodes deeper than depth with registered equivalents
+func (b *dawgBuilder) minimize(depth int) {
+	for len(b.unchecked) > depth {
+		i := len(b.unchecked) - 1
+		n := b.unchecked[i]
+		parent := b.root
+		if i > 0 {
+			parent = b.unchecked[i-1]
+		}
+		sig := n.signature()
+		if r, ok := b.register[sig]; ok {
+			parent.children[len(parent.children)-1] = r
+		} else {
+			n.id = b.nodes
+			b.nodes++
+			b.register[sig] = n
+		}
+		b.unchecked = b.unchecked[:i]
+	}
+}
+
+// finish lays the minimized nodes out as runs of edges
+func (b *dawgBuilder) finish() (*DAWG, error) {
+	b.minimize(0)
+
+	d := &DAWG{words: b.words}
+	d.edges = []uint32{0}
+	d.offsets = []uint32{0}
+	first := make(map[*buildNode]uint32)
+	counts := make(map[*buildNode]uint32)
+
+	var layout func(n *buildNode) (uint32, error)
+	layout = func(n *buildNode) (uint32, error) {
+		if len(n.children) == 0 {
+			// Leaves have no edges of their own, but always end a word
+			counts[n] = 1
+			return 0, nil
+		}
+		if f, ok := first[n]; ok {
+			return f, nil
+		}
+		start := uint32(len(d.edges))
+		if len(d.edges)+len(n.children) > maxEdges {
+			return 0, errors.New("dictionary too large")
+		}
+		for range n.children {
+			d.edges = append(d.edges, 0)
+			d.offsets = append(d.offsets, 0)
+		}
+		first[n] = start
+		count := uint32(0)
+		for i, c := range n.children {
+			target, err := layout(c)
+			if err != nil {
+				return 0, err
+			}
+			edge := uint32(n.letters[i]-'A') | target<<edgeTargetBits
+			if c.terminal {
+				edge |= edgeTerminal
+			}
+			if i == len(n.children)-1 {
+				edge |= edgeLast
+			}
+			d.edges[start+uint32(i)] = edge
+			d.offsets[start+uint32(i)] = count
+			count += counts[c]
+		}
+		if n.terminal {
+			count++
+		}
+		counts[n] = count
+		return start, nil
+	}
+
+	root, err := layout(b.root)
+	if err != nil {
+		return nil, err
+	}
+	d.root = root
+	return d, nil
+}
+
+// BuildDAWG builds a DAWG from a list of words, which need not be sorted or unique.
+// Words must be uppercase and contain only the letters A through Z.
+func BuildDAWG(words []string) (*DAWG, error) {
+	sorted := make([]string, len(words))
+	copy(sorted, words)
+	sort.Strings(sorted)
+
+	b := newDAWGBuilder()
+	for i, w := range sorted {
+		if i > 0 && w == sorted[i-1] {
+			continue
+		}
+		if strings.IndexByte(w, gaddagSeparator) >= 0 {
+			return nil, fmt.Errorf("invalid character %q in %q", gaddagSeparator, w)
+		}
+		if err := b.add(w); err != nil {
+			return nil, err
+		}
+	}
+	return b.finish()
+}
+
+// BuildGADDAG builds a GADDAG from a list of words.  A GADDAG holds, for every way of splitting a word
+// into a non-empty prefix and a suffix, the reversed prefix followed by a separator and the suffix,
+// so that every word can be found by starting from any one of its letters and working outward.
+// The separator is omitted when the suffix is empty; it is followed with Separator.
+func BuildGADDAG(words []string) (*DAWG, error) {
+	var entries []string
+	for _, w := range words {
+		for i := 1; i <= len(w); i++ {
+			rev := make([]byte, 0, len(w)+1)
+			for j := i - 1; j >= 0; j-- {
+				rev = append(rev, w[j])
+			}
+			if i < len(w) {
+				rev = append(rev, gaddagSeparator)
+				rev = append(rev, w[i:]...)
+			}
+			entries = append(entries, string(rev))
+		}
+	}
+	sort.Strings(entries)
+
+	b := newDAWGBuilder()
+	for i, e := range entries {
+		if i > 0 && e == entries[i-1] {
+			continue
+		}
+		if err := b.add(e); err != nil {
+			return nil, err
+		}
+	}
+	return b.finish()
+}
+
+// WriteTo writes the DAWG in the format read by ReadDAWG.  Word values are not saved.
+func (d *DAWG) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, dawgHeaderSize)
+	copy(header, dawgMagic)
+	binary.LittleEndian.PutUint32(header[8:], uint32(len(d.edges)))
+	binary.LittleEndian.PutUint32(header[12:], d.root)
+	binary.LittleEndian.PutUint32(header[16:], uint32(d.words))
+
+	body := make([]byte, 8*len(d.edges))
+	for i, e := range d.edges {
+		binary.LittleEndian.PutUint32(body[4*i:], e)
+	}
+	for i, o := range d.offsets {
+		binary.LittleEndian.PutUint32(body[4*(len(d.edges)+i):], o)
+	}
+
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(body)
+	return int64(n + m), err
+}
+
+// isDAWGFile returns true if the file begins with the DAWG magic number
+func isDAWGFile(filename string) (bool, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	magic := make([]byte, len(dawgMagic))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false, nil
+	}
+	return bytes.Equal(magic, dawgMagic), nil
+}
+
+// parseDAWGHeader checks the header of a DAWG file and returns the number of edges, the root, and the number of words
+func parseDAWGHeader(data []byte) (int, uint32, int, error) {
+	if len(data) < dawgHeaderSize || !bytes.Equal(data[:len(dawgMagic)], dawgMagic) {
+		return 0, 0, 0, errors.New("not a DAWG file")
+	}
+	n := int(binary.LittleEndian.Uint32(data[8:]))
+	root := binary.LittleEndian.Uint32(data[12:])
+	words := int(binary.LittleEndian.Uint32(data[16:]))
+	if n < 1 || len(data) != dawgHeaderSize+8*n || int(root) >= n {
+		return 0, 0, 0, errors.New("truncated or corrupt DAWG file")
+	}
+	return n, root, words, nil
+}
+
+// ReadDAWG reads a DAWG written by WriteTo into memory
+func ReadDAWG(filename string) (*DAWG, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	n, root, words, err := parseDAWGHeader(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	d := &DAWG{edges: make([]uint32, n), offsets: make([]uint32, n), root: root, words: words}
+	body := data[dawgHeaderSize:]
+	for i := range d.edges {
+		d.edges[i] = binary.LittleEndian.Uint32(body[4*i:])
+		d.offsets[i] = binary.LittleEndian.Uint32(body[4*(n+i):])
+	}
+	return d, nil
+}
+
+// dictionaries caches every dictionary loaded so far by file name, so that every solver shares a single copy
+var dictionaries = struct {
+	sync.Mutex
+	m map[string]*DAWG
+}{m: make(map[string]*DAWG)}
+
+// loadDictionary returns the words of a dictionary file, which may be a word list or a DAWG file written by
+// the compile command.  DAWG files are memory-mapped where possible, and word lists log what was dropped.
+// Each file is only loaded once.
+func loadDictionary(dictfile string) (*DAWG, error) {
+	dictionaries.Lock()
+	defer dictionaries.Unlock()
+	if d, ok := dictionaries.m[dictfile]; ok {
+		return d, nil
+	}
+
+	var d *DAWG
+	compiled, err := isDAWGFile(dictfile)
+	if err != nil {
+		return nil, err
+	}
+	if compiled {
+		d, err = mapDAWG(dictfile)
+	} else {
+		var words []string
+		var stats wordListStats
+		if words, stats, err = readWordListFile(dictfile, defaultWordListOptions); err == nil {
+			log.Printf("%s: %s", dictfile, stats)
+			d, err = BuildDAWG(words)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	dictionaries.m[dictfile] = d
+	return d, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+// mapDAWG reads a DAWG file written by WriteTo.  Memory mapping is only supported on Unix.
+func mapDAWG(filename string) (*DAWG, error) {
+	return ReadDAWG(filename)
+}
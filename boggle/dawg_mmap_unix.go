@@ -0,0 +1,47 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// mapDAWG memory-maps a DAWG file written by WriteTo.  The mapping is shared by every solver and is never unmapped.
+// Hosts that are not little-endian read the file into memory instead.
+func mapDAWG(filename string) (*DAWG, error) {
+	if !littleEndian() {
+		return ReadDAWG(filename)
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < dawgHeaderSize {
+		return nil, fmt.Errorf("%s: not a DAWG file", filename)
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	n, root, words, err := parseDAWGHeader(data)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	body := (*uint32)(unsafe.Pointer(&data[dawgHeaderSize]))
+	all := unsafe.Slice(body, 2*n)
+	return &DAWG{edges: all[:n:n], offsets: all[n:], root: root, words: words, data: data}, nil
+}
+
+func littleEndian() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}
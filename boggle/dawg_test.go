@@ -0,0 +1,164 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestDAWG(t *testing.T) {
+	words := []string{"TOPS", "TAP", "TOP", "TAPS", "TAP", "STOP", "STOPS", "A"}
+	d, err := BuildDAWG(words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"A", "STOP", "STOPS", "TAP", "TAPS", "TOP", "TOPS"}
+	var got []string
+	d.Each(func(id int, w string) {
+		if id != len(got) {
+			t.Errorf("word %s numbered %d, expected %d", w, id, len(got))
+		}
+		got = append(got, w)
+	})
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("words %v, expected %v", got, expected)
+	}
+	if d.Words() != len(expected) {
+		t.Errorf("%d words, expected %d", d.Words(), len(expected))
+	}
+
+	for id, w := range expected {
+		n, ok := d.Root().Subtrie(w)
+		if !ok || n.WordID() != id {
+			t.Errorf("word %s has number %d, expected %d", w, n.WordID(), id)
+		}
+	}
+	for _, w := range []string{"", "T", "TA", "TO", "STO", "TAPE", "Z"} {
+		if d.Has(w) {
+			t.Errorf("DAWG has non-word %q", w)
+		}
+	}
+
+	// The suffixes "P" and "PS" of TAP, TOP, and STOP are shared, as are the "OP" and "OPS" of STOP and TOP
+	if len(d.edges)-1 > 9 {
+		t.Errorf("DAWG has %d edges, expected at most 9", len(d.edges)-1)
+	}
+
+	scored := d.WithValues(func(w string) int { return len(w) })
+	if v := scored.Get("STOPS"); v != 5 {
+		t.Errorf("STOPS has value %d, expected 5", v)
+	}
+	if d.Get("STOPS") != 1 {
+		t.Error("values leaked into the shared DAWG")
+	}
+
+	if _, err := BuildDAWG([]string{"NO-GO"}); err == nil {
+		t.Error("built a DAWG with a hyphenated word")
+	}
+}
+
+func TestDAWGMatchesWordList(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-common.txt")
+	words, err := readWordList(dictfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := BuildDAWG(words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(words)
+	unique := words[:0]
+	for i, w := range words {
+		if i == 0 || w != words[i-1] {
+			unique = append(unique, w)
+		}
+	}
+	i := 0
+	d.Each(func(id int, w string) {
+		if i < len(unique) && w != unique[i] {
+			t.Fatalf("word %d is %s, expected %s", i, w, unique[i])
+		}
+		i++
+	})
+	if i != len(unique) {
+		t.Errorf("DAWG holds %d words, expected %d", i, len(unique))
+	}
+}
+
+func TestDAWGFile(t *testing.T) {
+	d, err := BuildDAWG([]string{"QUA", "QUIT", "QUOTE", "QUOTA", "AQUA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "words.dawg")
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.WriteTo(file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	for name, open := range map[string]func(string) (*DAWG, error){"read": ReadDAWG, "map": mapDAWG, "load": loadDictionary} {
+		loaded, err := open(filename)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(loaded.edges, d.edges) || !reflect.DeepEqual(loaded.offsets, d.offsets) || loaded.words != d.words {
+			t.Errorf("%s: loaded DAWG differs from the one written", name)
+		}
+		n, ok := loaded.Root().SubtrieR('Q')
+		if !ok {
+			t.Fatalf("%s: no words start with Q", name)
+		}
+		if n, ok = n.SubtrieR('A'); !ok || !n.IsWord() {
+			t.Errorf("%s: QUA not found by following Q and A", name)
+		}
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("BOGDAWG\x01truncated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadDAWG(filename); err == nil {
+		t.Error("read a truncated DAWG file")
+	}
+}
+
+func TestGADDAG(t *testing.T) {
+	g, err := BuildGADDAG([]string{"CARE", "CAR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Starting from the R of CARE: reversed prefix RAC, then the separator, then the suffix E
+	n, ok := g.Root().Subtrie("RAC")
+	if !ok || !n.IsWord() {
+		t.Fatal("RAC (CAR read backwards from R) not found")
+	}
+	if n, ok = n.Separator(); !ok {
+		t.Fatal("no separator after RAC")
+	}
+	if n, ok = n.SubtrieR('E'); !ok || !n.IsWord() {
+		t.Error("RAC+E not found")
+	}
+
+	for _, entry := range []string{"ERAC", "C", "AC", "A"} {
+		n, ok := g.Root().Subtrie(entry)
+		if !ok {
+			t.Errorf("prefix %s not found", entry)
+		} else if entry == "ERAC" && !n.IsWord() {
+			t.Errorf("%s is not an entry", entry)
+		}
+	}
+	// CAR and CARE have one entry per letter each
+	if g.Words() != 7 {
+		t.Errorf("%d entries, expected 7", g.Words())
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode"
+)
+
+// Die is a single letter cube, listing the letters printed on each face.
+// A face may hold more than one letter (like "QU" or "TH") or be blank (the empty string).
+// Dice in the same set may have different numbers of faces.
+type Die []string
+
+// blankFace is how blank faces are written in dice files and board text
+const blankFace = "."
+
+// parseFace normalizes the text of a single face.
+// A lone Q is always read as "QU", matching the printing on the classic dice.
+func parseFace(text string) (string, error) {
+	face := strings.ToUpper(text)
+	if face == blankFace {
+		return "", nil
+	}
+	if face == "Q" {
+		face = "QU"
+	}
+	if face == "" {
+		return "", errors.New("empty face")
+	}
+	for _, r := range face {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("invalid character %q in face %q", r, text)
+		}
+	}
+	return face, nil
+}
+
+// ParseDie reads a die from a line of text.
+// Faces are separated by whitespace; a line with no whitespace is read as one single-letter face per character.
+func ParseDie(text string) (Die, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 1 {
+		fields = strings.Split(fields[0], "")
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("die has no faces")
+	}
+	die := make(Die, len(fields))
+	for i, f := range fields {
+		face, err := parseFace(f)
+		if err != nil {
+			return nil, err
+		}
+		die[i] = face
+	}
+	return die, nil
+}
+
+func mustParseDice(dice []string) []Die {
+	set := make([]Die, len(dice))
+	for i, d := range dice {
+		die, err := ParseDie(d)
+		if err != nil {
+			panic(err)
+		}
+		set[i] = die
+	}
+	return set
+}
+
+// diceSets are the built-in dice sets by name
+var diceSets = map[string][]Die{
+	"1992":   mustParseDice(boggle1992),
+	"1983":   mustParseDice(boggle1983),
+	"master": mustParseDice(boggleMaster),
+	"big":    mustParseDice(boggleBig),
+}
+
+// LookupDice returns the built-in dice set with the given name
+func LookupDice(name string) ([]Die, error) {
+	dice, ok := diceSets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown dice set %q", name)
+	}
+	return dice, nil
+}
+
+// LoadDice returns the built-in dice set with the given name, or reads the dice from the named file if there is no such set
+func LoadDice(name string) ([]Die, error) {
+	if dice, err := LookupDice(name); err == nil {
+		return dice, nil
+	}
+	return ReadDice(name)
+}
+
+// ReadDice reads a dice set from a file.
+// JSON files hold an array of dice, each of which is either an array of faces or a string in the text format.
+// Text files hold one die per line in the format understood by ParseDie, ignoring blank lines and lines beginning with '#'.
+func ReadDice(filename string) ([]Die, error) {
+	bs, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var dice []Die
+	if t := bytes.TrimLeftFunc(bs, unicode.IsSpace); len(t) > 0 && t[0] == '[' {
+		dice, err = parseDiceJSON(bs)
+	} else {
+		dice, err = parseDiceText(bs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(dice) == 0 {
+		return nil, fmt.Errorf("%s: no dice", filename)
+	}
+	return dice, nil
+}
+
+func parseDiceText(bs []byte) ([]Die, error) {
+	var dice []Die
+	scanner := bufio.NewScanner(bytes.NewReader(bs))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		die, err := ParseDie(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		dice = append(dice, die)
+	}
+	return dice, scanner.Err()
+}
+
+func parseDiceJSON(bs []byte) ([]Die, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(bs, &raw); err != nil {
+		return nil, err
+	}
+	dice := make([]Die, len(raw))
+	for i, r := range raw {
+		var compact string
+		if err := json.Unmarshal(r, &compact); err == nil {
+			die, err := ParseDie(compact)
+			if err != nil {
+				return nil, fmt.Errorf("die %d: %v", i, err)
+			}
+			dice[i] = die
+			continue
+		}
+		var faces []string
+		if err := json.Unmarshal(r, &faces); err != nil {
+			return nil, fmt.Errorf("die %d: %v", i, err)
+		}
+		if len(faces) == 0 {
+			return nil, fmt.Errorf("die %d: die has no faces", i)
+		}
+		dice[i] = make(Die, len(faces))
+		for j, f := range faces {
+			face := ""
+			if f != "" {
+				var err error
+				if face, err = parseFace(f); err != nil {
+					return nil, fmt.Errorf("die %d: %v", i, err)
+				}
+			}
+			dice[i][j] = face
+		}
+	}
+	return dice, nil
+}
+
+// ValidateDice checks that a dice set has exactly one die for every cell of a rows-by-cols board
+func ValidateDice(dice []Die, rows int, cols int) error {
+	if len(dice) != rows*cols {
+		return fmt.Errorf("%d dice cannot fill a %d-by-%d board", len(dice), rows, cols)
+	}
+	for i, d := range dice {
+		if len(d) == 0 {
+			return fmt.Errorf("die %d has no faces", i)
+		}
+	}
+	return nil
+}
+
+// faceString formats a face for display, for example "Qu", "Th", or "." for a blank face
+func faceString(face string) string {
+	if face == "" {
+		return blankFace
+	}
+	return face[:1] + strings.ToLower(face[1:])
+}
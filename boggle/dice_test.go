@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDie(t *testing.T) {
+	tests := []struct {
+		text string
+		die  Die
+	}{
+		{"NMIQHU", Die{"N", "M", "I", "QU", "H", "U"}},
+		{"Qu Th In Er He An", Die{"QU", "TH", "IN", "ER", "HE", "AN"}},
+		{"a b . c", Die{"A", "B", "", "C"}},
+	}
+	for _, tt := range tests {
+		die, err := ParseDie(tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(die, tt.die) {
+			t.Errorf("die %q parsed as %v, expected %v", tt.text, die, tt.die)
+		}
+	}
+
+	for _, text := range []string{"", "AB1", "Qu T-h"} {
+		if _, err := ParseDie(text); err == nil {
+			t.Errorf("die %q parsed without error", text)
+		}
+	}
+}
+
+func TestReadDice(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"dice.txt":  "# mixed dice\nABCDEF\nQu Th In Er He An\nA B C . . .\nAEIOU\n",
+		"dice.json": `["ABCDEF", ["Qu", "Th", "In", "Er", "He", "An"], ["A", "B", "C", "", "", "."], "AEIOU"]`,
+	}
+	expected := []Die{
+		{"A", "B", "C", "D", "E", "F"},
+		{"QU", "TH", "IN", "ER", "HE", "AN"},
+		{"A", "B", "C", "", "", ""},
+		{"A", "E", "I", "O", "U"},
+	}
+
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dice, err := LoadDice(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dice, expected) {
+			t.Errorf("%s: read %v, expected %v", name, dice, expected)
+		}
+		if err := ValidateDice(dice, 2, 2); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if err := ValidateDice(dice, 2, 3); err == nil {
+			t.Errorf("%s: 4 dice validated for a 2-by-3 board", name)
+		}
+
+		f2 := make([][]float64, 26)
+		for i := range f2 {
+			f2[i] = make([]float64, 26)
+		}
+		rng := rand.New(rand.NewSource(1))
+		board := newDiceBoard(rng, 2, 2, dice)
+		for i := 0; i < 100; i++ {
+			board.DictShuffle(rng, buildAdjList(2, 2), f2)
+		}
+	}
+}
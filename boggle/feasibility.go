@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// maxProbabilityStates limits the work of RollProbability, which grows with the product of one more than
+// the number of cells holding each distinct face
+const maxProbabilityStates = 1 << 24
+
+// MatchDice finds a die and face for every cell of the board, assigning each die to one cell, by bipartite matching.
+// It returns the assignment as a DiceBoard, or an error naming a cell that no die can fill if there is none.
+func MatchDice(bb Boggler, dice []Die) (*DiceBoard, error) {
+	if err := ValidateDice(dice, bb.Rows(), bb.Cols()); err != nil {
+		return nil, err
+	}
+	cells := make([]string, bb.Rows()*bb.Cols())
+	for c := range cells {
+		cells[c] = bb.GetLinear(c)
+	}
+	dieOf, missing := matchDice(cells, dice, false)
+	if missing >= 0 {
+		return nil, fmt.Errorf("no die is left for the %s at %s", faceString(cells[missing]), Cell{Row: missing / bb.Cols(), Col: missing % bb.Cols()})
+	}
+
+	die := make([][]int, bb.Rows())
+	face := make([][]int, bb.Rows())
+	for i := range die {
+		die[i] = make([]int, bb.Cols())
+		face[i] = make([]int, bb.Cols())
+	}
+	for c, d := range dieOf {
+		i, j := c/bb.Cols(), c%bb.Cols()
+		die[i][j] = d
+		face[i][j] = dice[d].faceOf(cells[c])
+	}
+	return &DiceBoard{rows: bb.Rows(), cols: bb.Cols(), dice: dice, die: die, face: face}, nil
+}
+
+// matchDice assigns a different die to each cell, which must have a face showing the cell's letters, by Kuhn's algorithm.
+// If open is true, empty cells are left unassigned to be filled by any die left over.
+// It returns the die assigned to each cell, or -1, and the first cell that no die can fill, or -1 if every cell is filled.
+func matchDice(cells []string, dice []Die, open bool) ([]int, int) {
+	// fits lists the dice with a face matching each cell
+	fits := make([][]int, len(cells))
+	for c := range fits {
+		if open && cells[c] == "" {
+			continue
+		}
+		for d, die := range dice {
+			if die.faceOf(cells[c]) >= 0 {
+				fits[c] = append(fits[c], d)
+			}
+		}
+	}
+
+	// Find an augmenting path from each cell in turn
+	cellOf := make([]int, len(dice))
+	for d := range cellOf {
+		cellOf[d] = -1
+	}
+	seen := make([]bool, len(dice))
+	var augment func(c int) bool
+	augment = func(c int) bool {
+		for _, d := range fits[c] {
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+			if cellOf[d] < 0 || augment(cellOf[d]) {
+				cellOf[d] = c
+				return true
+			}
+		}
+		return false
+	}
+	missing := -1
+	for c := range cells {
+		if open && cells[c] == "" {
+			continue
+		}
+		for d := range seen {
+			seen[d] = false
+		}
+		if !augment(c) {
+			missing = c
+			break
+		}
+	}
+
+	dieOf := make([]int, len(cells))
+	for c := range dieOf {
+		dieOf[c] = -1
+	}
+	for d, c := range cellOf {
+		if c >= 0 {
+			dieOf[c] = d
+		}
+	}
+	return dieOf, missing
+}
+
+// faceOf returns the index of the first face of the die showing the given letters, or -1 if there is none
+func (d Die) faceOf(letters string) int {
+	for i, f := range d {
+		if f == letters {
+			return i
+		}
+	}
+	return -1
+}
+
+// RollProbability returns the probability that shaking the dice into the grid rolls exactly this board.
+// Each die lands in each cell with equal probability and shows each of its faces with equal probability.
+//
+// The probability is the permanent of the matrix giving the chance of each die showing each cell's face,
+// divided by n!.  Cells showing the same face are interchangeable, so rather than sum over every assignment
+// of dice to cells, it sums over assignments of dice to faces, counting how many of each face are used so far.
+func RollProbability(bb Boggler, dice []Die) (float64, error) {
+	if err := ValidateDice(dice, bb.Rows(), bb.Cols()); err != nil {
+		return 0, err
+	}
+	var faces []string
+	index := make(map[string]int)
+	var need []int
+	for _, f := range bb.ArrayLinear() {
+		i, ok := index[f]
+		if !ok {
+			i = len(faces)
+			index[f] = i
+			faces = append(faces, f)
+			need = append(need, 0)
+		}
+		need[i]++
+	}
+
+	// States number the faces used so far in mixed radix, where face i has radix need[i]+1
+	stride := make([]int, len(faces))
+	states := 1
+	for i, m := range need {
+		stride[i] = states
+		if states > maxProbabilityStates/(m+1) {
+			return 0, errors.New("board has too many distinct faces to compute its probability")
+		}
+		states *= m + 1
+	}
+
+	p := make([]float64, states)
+	p[0] = 1
+	chance := make([]float64, len(faces))
+	for _, die := range dice {
+		for i, f := range faces {
+			chance[i] = 0
+			for _, df := range die {
+				if faceString(df) == f {
+					chance[i]++
+				}
+			}
+			chance[i] /= float64(len(die))
+		}
+		// Each die must show some face, so each state is replaced by the sum over its predecessors.
+		// Predecessors have lower numbers, so going downward reads them before they are replaced.
+		for s := states - 1; s >= 0; s-- {
+			sum := 0.
+			for i := range faces {
+				if chance[i] > 0 && (s/stride[i])%(need[i]+1) > 0 {
+					sum += p[s-stride[i]] * chance[i]
+				}
+			}
+			p[s] = sum
+		}
+	}
+
+	// Each assignment of dice to faces is m! assignments to cells for each face that appears m times,
+	// and the dice land in each of their n! orders with equal probability
+	prob := p[states-1]
+	for _, m := range need {
+		for k := 2; k <= m; k++ {
+			prob *= float64(k)
+		}
+	}
+	for k := 2; k <= len(dice); k++ {
+		prob /= float64(k)
+	}
+	return prob, nil
+}
+
+// boardSymmetries returns the distinct boards made by rotating and reflecting a board.
+// Square boards have up to eight, and other boards up to four; the board itself is always first.
+func boardSymmetries(bb Boggler) []*BoggleBoard {
+	rows, cols := bb.Rows(), bb.Cols()
+	var boards []*BoggleBoard
+	seen := make(map[string]bool)
+	for _, t := range dihedral(rows, cols) {
+		board := make([][]string, rows)
+		for i := range board {
+			board[i] = make([]string, cols)
+		}
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				ti, tj := t(i, j)
+				board[ti][tj] = bb.Get(i, j)
+			}
+		}
+		b := &BoggleBoard{rows: rows, cols: cols, board: board}
+		if key := strings.Join(b.ArrayLinear(), " "); !seen[key] {
+			seen[key] = true
+			boards = append(boards, b)
+		}
+	}
+	return boards
+}
+
+// RollProbabilitySymmetric returns the probability of rolling the board or any of its rotations and reflections
+func RollProbabilitySymmetric(bb Boggler, dice []Die) (float64, error) {
+	total := 0.
+	for _, b := range boardSymmetries(bb) {
+		p, err := RollProbability(b, dice)
+		if err != nil {
+			return 0, err
+		}
+		total += p
+	}
+	return total, nil
+}
+
+// formatOdds writes a probability as odds of one in some number
+func formatOdds(p float64) string {
+	if p == 0 {
+		return "impossible"
+	}
+	return fmt.Sprintf("%.6g (1 in %.4g)", p, 1/p)
+}
+
+func runFeasible(args []string) error {
+	fs := flag.NewFlagSet("feasible", flag.ExitOnError)
+	diceName := fs.String("dice", "1992", "dice set (1992, 1983, master, big, or a dice file)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: feasible [flags] board-file...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("feasible requires at least one board file")
+	}
+	dice, err := LoadDice(*diceName)
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range fs.Args() {
+		board, err := ReadBoggleBoard(fn)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n%s\n", fn, board)
+		assignment, err := MatchDice(board, dice)
+		if err != nil {
+			fmt.Printf("cannot be rolled with the %s dice: %v\n\n", *diceName, err)
+			continue
+		}
+		fmt.Printf("dice:\n")
+		for i := 0; i < board.Rows(); i++ {
+			for j := 0; j < board.Cols(); j++ {
+				d := assignment.die[i][j]
+				fmt.Printf("  %s %-3s die %2d (%s)\n", Cell{Row: i, Col: j}, faceString(board.Get(i, j)), d, strings.Join(assignment.dice[d].faceStrings(), " "))
+			}
+		}
+
+		p, err := RollProbability(board, dice)
+		if err != nil {
+			return err
+		}
+		ps, err := RollProbabilitySymmetric(board, dice)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("probability: %s\nwith rotations and reflections: %s\n\n", formatOdds(p), formatOdds(ps))
+	}
+	return nil
+}
+
+// faceStrings formats each face of the die for display
+func (d Die) faceStrings() []string {
+	s := make([]string, len(d))
+	for i, f := range d {
+		s[i] = faceString(f)
+	}
+	return s
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestMatchDice(t *testing.T) {
+	dice := diceSets["1992"]
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		board := NewBoggleBoard(rng)
+		assignment, err := MatchDice(board, dice)
+		if err != nil {
+			t.Fatalf("%s: %v", board, err)
+		}
+		if assignment.String() != board.String() {
+			t.Errorf("assignment\n%s\ndoes not show board\n%s", assignment, board)
+		}
+		used := make(map[int]bool)
+		for _, row := range assignment.die {
+			for _, d := range row {
+				used[d] = true
+			}
+		}
+		if len(used) != len(dice) {
+			t.Errorf("assignment uses %d different dice", len(used))
+		}
+	}
+
+	// The 1992 set has a single die with an X
+	board, err := NewBoggleBoardArray([][]string{
+		{"X", "E", "A", "T"},
+		{"S", "E", "A", "T"},
+		{"S", "E", "A", "T"},
+		{"S", "E", "A", "X"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MatchDice(board, dice); err == nil {
+		t.Errorf("matched dice to a board with two Xs")
+	}
+	if p, err := RollProbability(board, dice); err != nil || p != 0 {
+		t.Errorf("board with two Xs rolled with probability %g, %v", p, err)
+	}
+}
+
+func TestRollProbability(t *testing.T) {
+	dice := []Die{{"A", "B"}, {"A", "C"}}
+	for _, tc := range []struct {
+		board  [][]string
+		p, sym float64
+	}{
+		{[][]string{{"A", "A"}}, 1. / 4, 1. / 4},
+		{[][]string{{"A", "B"}}, 1. / 8, 1. / 4},
+		{[][]string{{"B", "C"}}, 1. / 8, 1. / 4},
+		{[][]string{{"B", "B"}}, 0, 0},
+	} {
+		board, err := NewBoggleBoardArray(tc.board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := RollProbability(board, dice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sym, err := RollProbabilitySymmetric(board, dice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(p-tc.p) > 1e-12 || math.Abs(sym-tc.sym) > 1e-12 {
+			t.Errorf("%v: probability %g and %g with symmetry, expected %g and %g", tc.board, p, sym, tc.p, tc.sym)
+		}
+	}
+
+	// Count every way four dice can land on a 2-by-2 board: 4! orders and 6^4 faces
+	dice = diceSets["1992"][:4]
+	counts := make(map[string]int)
+	perms := [][]int{}
+	var permute func(a []int, k int)
+	permute = func(a []int, k int) {
+		if k == len(a) {
+			perms = append(perms, append([]int(nil), a...))
+			return
+		}
+		for i := k; i < len(a); i++ {
+			a[k], a[i] = a[i], a[k]
+			permute(a, k+1)
+			a[k], a[i] = a[i], a[k]
+		}
+	}
+	permute([]int{0, 1, 2, 3}, 0)
+	total := 0
+	for _, perm := range perms {
+		for f := 0; f < 6*6*6*6; f++ {
+			key := ""
+			for c, ff := 0, f; c < 4; c, ff = c+1, ff/6 {
+				key += dice[perm[c]][ff%6] + " "
+			}
+			counts[key]++
+			total++
+		}
+	}
+	for key, n := range counts {
+		var cells []string
+		for _, f := range strings.Fields(key) {
+			cells = append(cells, f)
+		}
+		board, err := NewBoggleBoardArray([][]string{cells[:2], cells[2:]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := RollProbability(board, dice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := float64(n) / float64(total); math.Abs(p-expected) > 1e-12 {
+			t.Fatalf("%s: probability %g, expected %g", key, p, expected)
+		}
+	}
+}
+
+func TestBoardSymmetries(t *testing.T) {
+	for _, tc := range []struct {
+		board [][]string
+		n     int
+	}{
+		{[][]string{{"A", "B"}, {"C", "D"}}, 8},
+		{[][]string{{"A", "B"}, {"B", "A"}}, 2},
+		{[][]string{{"A", "A"}, {"A", "A"}}, 1},
+		{[][]string{{"A", "B", "C"}, {"D", "E", "F"}}, 4},
+		{[][]string{{"A", "B", "A"}}, 1},
+	} {
+		board, err := NewBoggleBoardArray(tc.board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		syms := boardSymmetries(board)
+		if len(syms) != tc.n || syms[0].String() != board.String() {
+			t.Errorf("%v has %d symmetries, expected %d", tc.board, len(syms), tc.n)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// Objective measures how far a board is from what is wanted.  A distance of zero means the board is acceptable.
+type Objective interface {
+	Distance(sol *Solution) float64
+}
+
+// difficulty is an Objective requiring a board's solution to fall inside a window.
+// Each unmet requirement adds to the distance in proportion to how far it is missed.
+type difficulty struct {
+	minScore int
+	// maxScore is the highest acceptable score, or zero for no limit
+	maxScore int
+	// minLongest is the length of the longest word required
+	minLongest int
+	// obscure words count against maxObscure; a negative maxObscure is no limit
+	obscure    map[string]bool
+	maxObscure int
+}
+
+// Distance implements Objective's interface
+func (d *difficulty) Distance(sol *Solution) float64 {
+	dist := 0.
+	if sol.Score < d.minScore {
+		dist += float64(d.minScore - sol.Score)
+	}
+	if d.maxScore > 0 && sol.Score > d.maxScore {
+		dist += float64(sol.Score - d.maxScore)
+	}
+
+	longest := 0
+	obscure := 0
+	for _, w := range sol.Words {
+		if len(w.Word) > longest {
+			longest = len(w.Word)
+		}
+		if d.obscure[w.Word] {
+			obscure++
+		}
+	}
+	// Missing letters and extra obscure words are harder to fix than a few points, so they weigh more
+	if longest < d.minLongest {
+		dist += 10 * float64(d.minLongest-longest)
+	}
+	if d.maxObscure >= 0 && obscure > d.maxObscure {
+		dist += 5 * float64(obscure-d.maxObscure)
+	}
+	return dist
+}
+
+// generate searches for a board rolled from the dice whose solution meets the objective.
+// Starting from a random roll, it makes the optimizer's moves, accepting each one by the Metropolis criterion
+// on distance from the objective.  It returns the closest board found and whether it meets the objective.
+func (bs *boggleSolver) generate(rng *rand.Rand, freqs [][]float64, opts optimizeOptions, obj Objective, steps int) (*DiceBoard, *Solution, bool) {
+	board := newDiceBoard(rng, opts.rows, opts.cols, opts.dice)
+	sol := bs.findWords(board, false)
+	dist := obj.Distance(sol)
+	best, bestSol, bestDist := board.Clone().(*DiceBoard), sol, dist
+
+	for i := 0; i < steps && bestDist > 0; i++ {
+		last, lastSol, lastDist := board.Clone().(*DiceBoard), sol, dist
+
+		board.DictShuffle(rng, bs.adjList, freqs)
+		sol = bs.findWords(board, false)
+		dist = obj.Distance(sol)
+		if dist > lastDist && rng.Float64() >= math.Exp(lastDist-dist) {
+			board, sol, dist = last, lastSol, lastDist
+			continue
+		}
+		if dist < bestDist {
+			best, bestSol, bestDist = board.Clone().(*DiceBoard), sol, dist
+		}
+	}
+	return best, bestSol, bestDist == 0
+}
+
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	rows := fs.Int("rows", 4, "number of rows on the board")
+	cols := fs.Int("cols", 4, "number of columns on the board")
+	diceName := fs.String("dice", "1992", "dice set (1992, 1983, master, big, or a dice file)")
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	minScore := fs.Int("min-score", 0, "lowest acceptable score")
+	maxScore := fs.Int("max-score", 0, "highest acceptable score (0 for no limit)")
+	minLongest := fs.Int("min-longest", 0, "require a word with at least this many letters")
+	obscureFile := fs.String("obscure", "", "file listing obscure words, one per line")
+	maxObscure := fs.Int("max-obscure", -1, "most obscure words allowed on the board (-1 for no limit)")
+	count := fs.Int("count", 1, "number of boards to generate")
+	attempts := fs.Int("attempts", 20, "number of fresh rolls to try for each board before giving up")
+	steps := fs.Int("steps", 2000, "moves to make from each roll")
+	seed := fs.Int64("seed", 0, "random seed (0 uses the clock)")
+	asJSON := fs.Bool("json", false, "print each board's solution as JSON")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Parse(args)
+
+	if *maxScore > 0 && *maxScore < *minScore {
+		return errors.New("-max-score is below -min-score")
+	}
+	obj := &difficulty{minScore: *minScore, maxScore: *maxScore, minLongest: *minLongest, maxObscure: *maxObscure}
+	if *obscureFile != "" {
+		var err error
+		if obj.obscure, err = readWordSet(*obscureFile); err != nil {
+			return err
+		}
+	}
+
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	dice, err := LoadDice(*diceName)
+	if err != nil {
+		return err
+	}
+	if err := ValidateDice(dice, *rows, *cols); err != nil {
+		return fmt.Errorf("dice set %s: %v", *diceName, err)
+	}
+	bs, err := newSolver(*rows, *cols, topo, *dictfile, r)
+	if err != nil {
+		return err
+	}
+	freqs, err := frequencyCount(*dictfile, r.MinLength(), *rows**cols)
+	if err != nil {
+		return err
+	}
+	opts := optimizeOptions{rows: *rows, cols: *cols, topology: topo, dice: dice, dictfile: *dictfile, rule: r}
+	rng, _ := newRandom(*seed)
+
+	for i := 0; i < *count; i++ {
+		var board *DiceBoard
+		var sol *Solution
+		ok := false
+		for a := 0; a < *attempts && !ok; a++ {
+			board, sol, ok = bs.generate(rng, freqs, opts, obj, *steps)
+		}
+		if !ok {
+			return fmt.Errorf("no board meeting the requirements found in %d attempts", *attempts)
+		}
+
+		if *asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(sol); err != nil {
+				return err
+			}
+			continue
+		}
+		longest := ""
+		for _, w := range sol.Words {
+			if len(w.Word) > len(longest) {
+				longest = w.Word
+			}
+		}
+		fmt.Printf("%s\n# score %d, %d words, longest %s\n\n", board, sol.Score, len(sol.Words), longest)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDifficultyDistance(t *testing.T) {
+	sol := &Solution{Score: 50, Words: []WordResult{{Word: "CAT", Score: 1}, {Word: "SCATTER", Score: 5}, {Word: "TEAS", Score: 1}}}
+	for _, c := range []struct {
+		d        difficulty
+		expected float64
+	}{
+		{difficulty{minScore: 40, maxScore: 60, maxObscure: -1}, 0},
+		{difficulty{minScore: 60, maxObscure: -1}, 10},
+		{difficulty{maxScore: 45, maxObscure: -1}, 5},
+		{difficulty{minLongest: 8, maxObscure: -1}, 10},
+		{difficulty{obscure: map[string]bool{"CAT": true, "TEAS": true}, maxObscure: 0}, 10},
+		{difficulty{obscure: map[string]bool{"CAT": true, "TEAS": true}, maxObscure: 2}, 0},
+	} {
+		if dist := c.d.Distance(sol); dist != c.expected {
+			t.Errorf("%+v: distance %f, expected %f", c.d, dist, c.expected)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	opts, bs, freqs := testOptimizer(t)
+
+	obj := &difficulty{minScore: 40, maxScore: 50, minLongest: 6, maxObscure: -1}
+	board, sol, ok := bs.generate(rand.New(rand.NewSource(7)), freqs, opts, obj, 5000)
+	if !ok {
+		t.Fatalf("no board found; closest scored %d:\n%s", sol.Score, board)
+	}
+	if s := bs.score(board); s != sol.Score || s < 40 || s > 50 {
+		t.Errorf("board scores %d, solution %d, expected 40 to 50", s, sol.Score)
+	}
+	if obj.Distance(bs.findWords(board, false)) != 0 {
+		t.Errorf("board does not meet the objective:\n%s", board)
+	}
+}
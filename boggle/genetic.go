@@ -0,0 +1,336 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// crossover makes a child board from two parents by taking the dice in some region of the board from the
+// first parent and the rest from the second
+type crossover func(rng *rand.Rand, a, b *DiceBoard, adjList [][]int) *DiceBoard
+
+// crossovers are the crossover operators by name
+var crossovers = map[string]crossover{
+	"rows":      rowCrossover,
+	"quadrants": quadrantCrossover,
+	"regions":   regionCrossover,
+}
+
+// parseCrossovers reads a comma-separated list of crossover operator names
+func parseCrossovers(text string) ([]crossover, error) {
+	var ops []crossover
+	for _, name := range strings.Split(text, ",") {
+		op, ok := crossovers[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown crossover %q (rows, quadrants, or regions)", name)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// rowCrossover takes a band of whole rows from the first parent
+func rowCrossover(rng *rand.Rand, a, b *DiceBoard, adjList [][]int) *DiceBoard {
+	first := rng.Intn(a.rows)
+	n := 1 + rng.Intn(max(a.rows-1, 1))
+	region := make([]bool, a.rows*a.cols)
+	for i := first; i < first+n && i < a.rows; i++ {
+		for j := 0; j < a.cols; j++ {
+			region[i*a.cols+j] = true
+		}
+	}
+	return splice(rng, a, b, region)
+}
+
+// quadrantCrossover cuts the board at a random row and column and takes one of the four pieces from the first parent
+func quadrantCrossover(rng *rand.Rand, a, b *DiceBoard, adjList [][]int) *DiceBoard {
+	row, col := 1+rng.Intn(max(a.rows-1, 1)), 1+rng.Intn(max(a.cols-1, 1))
+	top, left := rng.Intn(2) == 0, rng.Intn(2) == 0
+	region := make([]bool, a.rows*a.cols)
+	for i := 0; i < a.rows; i++ {
+		for j := 0; j < a.cols; j++ {
+			region[i*a.cols+j] = (i < row) == top && (j < col) == left
+		}
+	}
+	return splice(rng, a, b, region)
+}
+
+// regionCrossover grows a connected region of random size from a random cell, following the board's adjacency,
+// and takes it from the first parent.  Words lie along connected paths, so this keeps clusters of good letters together.
+func regionCrossover(rng *rand.Rand, a, b *DiceBoard, adjList [][]int) *DiceBoard {
+	n := len(adjList)
+	size := 1 + rng.Intn(max(n-1, 1))
+	region := make([]bool, n)
+	start := rng.Intn(n)
+	region[start] = true
+	frontier := []int{start}
+	for grown := 1; grown < size && len(frontier) > 0; {
+		k := rng.Intn(len(frontier))
+		p := frontier[k]
+		var open []int
+		for _, q := range adjList[p] {
+			if !region[q] {
+				open = append(open, q)
+			}
+		}
+		if len(open) == 0 {
+			frontier[k] = frontier[len(frontier)-1]
+			frontier = frontier[:len(frontier)-1]
+			continue
+		}
+		q := open[rng.Intn(len(open))]
+		region[q] = true
+		frontier = append(frontier, q)
+		grown++
+	}
+	return splice(rng, a, b, region)
+}
+
+// splice makes a child with the dice of the first parent inside the region and of the second parent outside it.
+// Dice cannot appear twice, so a cell outside the region whose die is already used gets one of the dice left
+// over, showing the second parent's letters there if any leftover die has them and a random face otherwise.
+func splice(rng *rand.Rand, a, b *DiceBoard, region []bool) *DiceBoard {
+	child := b.Clone().(*DiceBoard)
+	used := make([]bool, len(a.dice))
+	for k, in := range region {
+		if in {
+			i, j := k/a.cols, k%a.cols
+			child.die[i][j], child.face[i][j] = a.die[i][j], a.face[i][j]
+			used[a.die[i][j]] = true
+		}
+	}
+
+	var clashes []int
+	for k, in := range region {
+		i, j := k/b.cols, k%b.cols
+		if in {
+			continue
+		}
+		if used[b.die[i][j]] {
+			clashes = append(clashes, k)
+			continue
+		}
+		used[b.die[i][j]] = true
+	}
+	var left []int
+	for d, u := range used {
+		if !u {
+			left = append(left, d)
+		}
+	}
+
+	for _, k := range clashes {
+		i, j := k/b.cols, k%b.cols
+		want := b.Get(i, j)
+		pick, face := rng.Intn(len(left)), -1
+		for n, d := range left {
+			if f := a.dice[d].faceOf(want); f >= 0 {
+				pick, face = n, f
+				break
+			}
+		}
+		d := left[pick]
+		left[pick] = left[len(left)-1]
+		left = left[:len(left)-1]
+		if face < 0 {
+			face = rng.Intn(len(a.dice[d]))
+		}
+		child.die[i][j], child.face[i][j] = d, face
+	}
+	return child
+}
+
+// individual is one board of the population with its score
+type individual struct {
+	board *DiceBoard
+	score int
+}
+
+// geneticParams configure the genetic method
+type geneticParams struct {
+	population int
+	// elite is the number of best boards carried unchanged into the next generation
+	elite int
+	// tournament is the number of boards drawn at random to pick each parent, the best of which wins
+	tournament int
+	// crossoverRate is the chance a child is bred from two parents rather than copied from one
+	crossoverRate float64
+	// mutationRate is the chance a child is mutated by DictShuffle
+	mutationRate float64
+	crossovers   []crossover
+	// workers is the number of boards scored concurrently
+	workers int
+}
+
+// genetic evolves a population of boards by tournament selection, crossover, and mutation, keeping the best boards
+// of each generation.  Children are bred in order from a single source, so a run is reproducible from its seed.
+type genetic struct {
+	solver *boggleSolver
+	freqs  [][]float64
+	params geneticParams
+	rng    *rand.Rand
+	src    *splitMix
+	// cache remembers the scores of boards already bred, which elitism and crossover make common
+	cache *scoreCache
+	// scorers belong to the workers scoring boards, one each
+	scorers []*scorer
+
+	// population is sorted from best to worst
+	population []individual
+
+	best      int
+	bestBoard []string
+}
+
+// newGenetic starts a population of random boards
+func newGenetic(seed int64, bs *boggleSolver, freqs [][]float64, opts optimizeOptions, params geneticParams) *genetic {
+	g := &genetic{solver: bs, freqs: freqs, params: params, population: make([]individual, params.population)}
+	g.cache = newScoreCache(opts.rows, opts.cols, bs.adjList, opts.cacheSize)
+	g.scorers = newScorers(bs, params.workers)
+	g.rng, g.src = newSplitMixRand(seed)
+	for i := range g.population {
+		g.population[i].board = newDiceBoard(g.rng, opts.rows, opts.cols, opts.dice)
+	}
+	g.evaluate(g.population)
+	g.sort()
+	return g
+}
+
+// restoreGenetic resumes a genetic run saved with checkpoint
+func restoreGenetic(cp *checkpoint, bs *boggleSolver, freqs [][]float64, opts optimizeOptions, params geneticParams) (*genetic, error) {
+	if len(cp.Workers) != params.population || len(cp.Swaps) != 0 {
+		return nil, fmt.Errorf("checkpoint is not from a genetic run with a population of %d", params.population)
+	}
+	g := &genetic{
+		solver:     bs,
+		freqs:      freqs,
+		params:     params,
+		src:        &splitMix{state: cp.RNG},
+		population: make([]individual, len(cp.Workers)),
+		best:       cp.Best,
+		bestBoard:  cp.BestBoard,
+		cache:      newScoreCache(opts.rows, opts.cols, bs.adjList, opts.cacheSize),
+		scorers:    newScorers(bs, params.workers),
+	}
+	g.rng = rand.New(g.src)
+	for i, w := range cp.Workers {
+		board, err := w.Board.restore(opts.rows, opts.cols, opts.dice)
+		if err != nil {
+			return nil, err
+		}
+		g.population[i] = individual{board: board, score: w.Score}
+	}
+	g.sort()
+	return g, nil
+}
+
+// checkpoint saves the population between generations
+func (g *genetic) checkpoint() *checkpoint {
+	cp := &checkpoint{RNG: g.src.state, Best: g.best, BestBoard: g.bestBoard, Workers: make([]workerState, len(g.population))}
+	for i, ind := range g.population {
+		cp.Workers[i] = workerState{Board: ind.board.state(), Score: ind.score}
+	}
+	return cp
+}
+
+// evaluate scores the boards concurrently
+func (g *genetic) evaluate(inds []individual) {
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for _, sc := range g.scorers {
+		wg.Add(1)
+		go func(sc *scorer) {
+			defer wg.Done()
+			for i := range next {
+				inds[i].score = g.cache.score(sc, inds[i].board)
+			}
+		}(sc)
+	}
+	for i := range inds {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+func newScorers(bs *boggleSolver, n int) []*scorer {
+	scorers := make([]*scorer, n)
+	for i := range scorers {
+		scorers[i] = newScorer(bs)
+	}
+	return scorers
+}
+
+// sort orders the population from best to worst and records the best board.
+// Ties keep their order, so the population's order depends only on the run's seed.
+func (g *genetic) sort() bool {
+	sort.SliceStable(g.population, func(i, j int) bool { return g.population[i].score > g.population[j].score })
+	if g.population[0].score > g.best {
+		g.best = g.population[0].score
+		g.bestBoard = g.population[0].board.ArrayLinear()
+		return true
+	}
+	return false
+}
+
+// selectParent picks a parent by tournament
+func (g *genetic) selectParent() *DiceBoard {
+	best := g.rng.Intn(len(g.population))
+	for k := 1; k < g.params.tournament; k++ {
+		// The population is sorted, so the lowest index drawn is the best board
+		if i := g.rng.Intn(len(g.population)); i < best {
+			best = i
+		}
+	}
+	return g.population[best].board
+}
+
+// generation replaces the population with its elite and children bred from it.
+// It returns true if a new best board was found.
+func (g *genetic) generation() bool {
+	next := make([]individual, len(g.population))
+	elite := min(g.params.elite, len(next))
+	copy(next, g.population[:elite])
+	for i := elite; i < len(next); i++ {
+		a := g.selectParent()
+		var child *DiceBoard
+		if g.rng.Float64() < g.params.crossoverRate {
+			b := g.selectParent()
+			op := g.params.crossovers[g.rng.Intn(len(g.params.crossovers))]
+			child = op(g.rng, a, b, g.solver.adjList)
+		} else {
+			child = a.Clone().(*DiceBoard)
+		}
+		if g.rng.Float64() < g.params.mutationRate {
+			child.DictShuffle(g.rng, g.solver.adjList, g.freqs)
+		}
+		next[i].board = child
+	}
+	g.evaluate(next[elite:])
+	g.population = next
+	return g.sort()
+}
+
+// validate checks the genetic method's settings
+func (p geneticParams) validate() error {
+	if p.population < 2 {
+		return errors.New("population must hold at least two boards")
+	}
+	if p.elite < 0 || p.elite >= p.population {
+		return errors.New("elite must be at least zero and smaller than the population")
+	}
+	if p.tournament < 1 {
+		return errors.New("tournament must draw at least one board")
+	}
+	if p.crossoverRate < 0 || p.crossoverRate > 1 || p.mutationRate < 0 || p.mutationRate > 1 {
+		return errors.New("crossover and mutation rates must be between 0 and 1")
+	}
+	if p.workers < 1 {
+		return errors.New("need at least one worker")
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCrossover(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, tc := range []struct {
+		rows, cols int
+		dice       string
+		topology   Topology
+	}{
+		{4, 4, "1992", GridTopology},
+		{5, 5, "big", HexTopology},
+	} {
+		dice := diceSets[tc.dice]
+		adjList, err := tc.topology.AdjList(tc.rows, tc.cols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, op := range crossovers {
+			for trial := 0; trial < 200; trial++ {
+				a := newDiceBoard(rng, tc.rows, tc.cols, dice)
+				b := newDiceBoard(rng, tc.rows, tc.cols, dice)
+				child := op(rng, a, b, adjList)
+
+				used := make([]bool, len(dice))
+				fromA, fromB := 0, 0
+				for i := 0; i < tc.rows; i++ {
+					for j := 0; j < tc.cols; j++ {
+						d, f := child.die[i][j], child.face[i][j]
+						if used[d] {
+							t.Fatalf("%s crossover used die %d twice", name, d)
+						}
+						used[d] = true
+						if f < 0 || f >= len(dice[d]) {
+							t.Fatalf("%s crossover chose face %d of die %d", name, f, d)
+						}
+						if d == a.die[i][j] && f == a.face[i][j] {
+							fromA++
+						}
+						if d == b.die[i][j] && f == b.face[i][j] {
+							fromB++
+						}
+					}
+				}
+				if fromA == 0 {
+					t.Fatalf("%s crossover took nothing from the first parent", name)
+				}
+				if fromA+fromB < tc.rows*tc.cols/2 {
+					t.Errorf("%s crossover kept only %d cells of the parents", name, fromA+fromB)
+				}
+			}
+		}
+	}
+}
+
+func TestGenetic(t *testing.T) {
+	opts, bs, freqs := testOptimizer(t)
+	ops, err := parseCrossovers("rows,quadrants,regions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := geneticParams{population: 30, elite: 2, tournament: 3, crossoverRate: 0.8, mutationRate: 0.5, crossovers: ops, workers: 4}
+	if err := params.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var boards [2][]string
+	for run := range boards {
+		g := newGenetic(8675309, bs, freqs, opts, params)
+		initial := g.best
+		for i := 0; i < 10; i++ {
+			g.generation()
+		}
+		if g.best < initial || g.best != g.population[0].score {
+			t.Errorf("best score went from %d to %d, with the best board scoring %d", initial, g.best, g.population[0].score)
+		}
+		for _, ind := range g.population {
+			if s := bs.score(ind.board); s != ind.score {
+				t.Fatalf("board recorded as scoring %d scores %d", ind.score, s)
+			}
+		}
+
+		// A restored run continues exactly as the original
+		cp := g.checkpoint()
+		restored, err := restoreGenetic(cp, bs, freqs, opts, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.generation()
+		restored.generation()
+		if !reflect.DeepEqual(g.population[0].board.ArrayLinear(), restored.population[0].board.ArrayLinear()) {
+			t.Errorf("restored run diverged")
+		}
+		boards[run] = g.bestBoard
+	}
+	if !reflect.DeepEqual(boards[0], boards[1]) {
+		t.Errorf("runs with the same seed found different boards %v and %v", boards[0], boards[1])
+	}
+
+	if _, err := parseCrossovers("rows,halves"); err == nil {
+		t.Errorf("parsed an unknown crossover")
+	}
+}
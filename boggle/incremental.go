@@ -0,0 +1,272 @@
+package main
+
+import (
+	"sync"
+)
+
+// incrementalScore keeps the number of paths spelling each word on a board, so that after a few cells
+// change the board can be rescored by visiting only the paths through those cells.
+//
+// Paths through the changed cells are found with the dictionary's GADDAG, starting from a changed cell and
+// working outward in both directions.  Each path is visited from the lowest-numbered changed cell on it, so
+// no path is counted twice.  Boards of more than 64 cells are rescored from scratch instead.
+type incrementalScore struct {
+	solver *boggleSolver
+	// kernel rescores boards from scratch when they cannot be rescored incrementally
+	kernel *scorer
+	gaddag *DAWG
+	// reverse lists the cells from which each cell can be reached
+	reverse [][]int
+	cells   []string
+	paths   []int
+	score   int
+
+	// journal records the change in path count made to each word by the last update, so it can be reverted
+	journal   []pathChange
+	changed   []int
+	lastCells []string
+	lastScore int
+}
+
+type pathChange struct {
+	word  int32
+	delta int32
+}
+
+// newIncremental counts the paths spelling each word on the board.
+// The scorer, which belongs to the caller, is used for boards that must be rescored from scratch.
+func (bs *boggleSolver) newIncremental(bb Boggler, kernel *scorer) *incrementalScore {
+	is := &incrementalScore{solver: bs, kernel: kernel, cells: make([]string, len(bs.adjList))}
+	for p := range is.cells {
+		is.cells[p] = bb.GetLinear(p)
+	}
+	if len(bs.adjList) > maxKernelCells {
+		is.score = kernel.score(bb)
+		return is
+	}
+
+	g, err := loadGADDAG(bs.dictfile)
+	if err != nil {
+		// The dictionary has already been loaded, so this should never happen; rescore from scratch if it does
+		is.score = kernel.score(bb)
+		return is
+	}
+	is.gaddag = g
+	is.reverse = make([][]int, len(bs.adjList))
+	for p, adj := range bs.adjList {
+		for _, p2 := range adj {
+			is.reverse[p2] = append(is.reverse[p2], p)
+		}
+	}
+	is.paths = make([]int, bs.dictionary.Words())
+	root := bs.dictionary.Root()
+	for p := range is.cells {
+		is.count(root, p, 0)
+	}
+	is.journal = is.journal[:0]
+	return is
+}
+
+// Score returns the score of the board as of the last update
+func (is *incrementalScore) Score() int {
+	return is.score
+}
+
+// update rescores the board after the given cells have changed and returns the new score.
+// Cells that have not really changed may be listed; they only cost time.
+func (is *incrementalScore) update(bb Boggler, changed []int) int {
+	is.journal = is.journal[:0]
+	is.changed = is.changed[:0]
+	is.lastCells = is.lastCells[:0]
+	is.lastScore = is.score
+	for _, p := range changed {
+		// A re-thrown die often lands on the same letters, and a moved die may be replaced by an identical one
+		if tile := bb.GetLinear(p); tile != is.cells[p] {
+			is.changed = append(is.changed, p)
+			is.lastCells = append(is.lastCells, is.cells[p])
+		}
+	}
+	changed = is.changed
+
+	if is.paths == nil {
+		for _, p := range changed {
+			is.cells[p] = bb.GetLinear(p)
+		}
+		is.score = is.kernel.score(bb)
+		return is.score
+	}
+
+	is.visitChanged(changed, -1)
+	for _, p := range changed {
+		is.cells[p] = bb.GetLinear(p)
+	}
+	is.visitChanged(changed, 1)
+	return is.score
+}
+
+// revert undoes the last update, restoring the path counts and score of the board before it changed
+func (is *incrementalScore) revert() {
+	for i := len(is.journal) - 1; i >= 0; i-- {
+		is.paths[is.journal[i].word] -= int(is.journal[i].delta)
+	}
+	is.journal = is.journal[:0]
+	for i, p := range is.changed {
+		is.cells[p] = is.lastCells[i]
+	}
+	is.score = is.lastScore
+}
+
+// visitChanged adds sign to the path count of every word along every path through a changed cell
+func (is *incrementalScore) visitChanged(changed []int, sign int) {
+	var mask uint64
+	for _, p := range changed {
+		mask |= 1 << uint(p)
+	}
+	for a := range is.cells {
+		bit := uint64(1) << uint(a)
+		if mask&bit == 0 || is.cells[a] == "" {
+			continue
+		}
+		// Paths through a lower-numbered changed cell are visited from that cell
+		visited := mask&(bit-1) | bit
+		n, ok := followReversed(is.gaddag.Root(), is.cells[a])
+		if ok {
+			is.backward(n, a, a, visited, sign)
+		}
+	}
+}
+
+// backward extends a path ending at anchor towards its start, first, reading the GADDAG's reversed prefixes
+func (is *incrementalScore) backward(n DAWGNode, first int, anchor int, visited uint64, sign int) {
+	if n.IsWord() {
+		is.record(n.RootValue(), sign)
+	}
+	if sep, ok := n.Separator(); ok {
+		is.forward(sep, anchor, visited, sign)
+	}
+	for _, p := range is.reverse[first] {
+		if visited&(1<<uint(p)) != 0 || is.cells[p] == "" {
+			continue
+		}
+		if next, ok := followReversed(n, is.cells[p]); ok {
+			is.backward(next, p, anchor, visited|1<<uint(p), sign)
+		}
+	}
+}
+
+// forward extends a path past its last cell, reading the suffix of a GADDAG entry
+func (is *incrementalScore) forward(n DAWGNode, last int, visited uint64, sign int) {
+	for _, p := range is.solver.adjList[last] {
+		if visited&(1<<uint(p)) != 0 || is.cells[p] == "" {
+			continue
+		}
+		next, ok := n.Subtrie(is.cells[p])
+		if !ok {
+			continue
+		}
+		if next.IsWord() {
+			is.record(next.RootValue(), sign)
+		}
+		if !next.Leaf() {
+			is.forward(next, p, visited|1<<uint(p), sign)
+		}
+	}
+}
+
+// count adds one to the path count of every word along every path starting at p
+func (is *incrementalScore) count(n DAWGNode, p int, visited uint64) {
+	tile := is.cells[p]
+	if tile == "" {
+		return
+	}
+	next, ok := n.Subtrie(tile)
+	if !ok {
+		return
+	}
+	if next.IsWord() {
+		is.record(next.WordID(), 1)
+	}
+	visited |= 1 << uint(p)
+	for _, p2 := range is.solver.adjList[p] {
+		if visited&(1<<uint(p2)) == 0 {
+			is.count(next, p2, visited)
+		}
+	}
+}
+
+func (is *incrementalScore) record(word int, sign int) {
+	value := is.solver.dictionary.Value(word)
+	if value == 0 {
+		return
+	}
+	before := is.paths[word]
+	is.paths[word] += sign
+	if before == 0 {
+		is.score += value
+	} else if is.paths[word] == 0 {
+		is.score -= value
+	}
+	is.journal = append(is.journal, pathChange{word: int32(word), delta: int32(sign)})
+}
+
+// followReversed follows the letters of a tile from last to first, as they appear in a GADDAG's reversed prefixes
+func followReversed(n DAWGNode, tile string) (DAWGNode, bool) {
+	for i := len(tile) - 1; i >= 0; i-- {
+		var ok bool
+		if n, ok = n.child(tile[i]); !ok {
+			return n, false
+		}
+	}
+	return n, true
+}
+
+// gaddags caches the GADDAG of each dictionary file, whose entries are valued with the numbers of their words
+var gaddags = struct {
+	sync.Mutex
+	m map[string]*DAWG
+}{m: make(map[string]*DAWG)}
+
+// loadGADDAG returns the GADDAG of a dictionary file, building it the first time it is needed.
+// Building a GADDAG takes several seconds for the larger dictionaries.
+func loadGADDAG(dictfile string) (*DAWG, error) {
+	gaddags.Lock()
+	defer gaddags.Unlock()
+	if g, ok := gaddags.m[dictfile]; ok {
+		return g, nil
+	}
+
+	d, err := loadDictionary(dictfile)
+	if err != nil {
+		return nil, err
+	}
+	var words []string
+	d.Each(func(_ int, w string) {
+		words = append(words, w)
+	})
+	g, err := BuildGADDAG(words)
+	if err != nil {
+		return nil, err
+	}
+	g = g.WithValues(func(entry string) int {
+		return d.WordID(gaddagWord(entry))
+	})
+	gaddags.m[dictfile] = g
+	return g, nil
+}
+
+// gaddagWord recovers the word from a GADDAG entry
+func gaddagWord(entry string) string {
+	prefix := entry
+	suffix := ""
+	for i := 0; i < len(entry); i++ {
+		if entry[i] == gaddagSeparator {
+			prefix, suffix = entry[:i], entry[i+1:]
+			break
+		}
+	}
+	word := make([]byte, 0, len(entry))
+	for i := len(prefix) - 1; i >= 0; i-- {
+		word = append(word, prefix[i])
+	}
+	return string(append(word, suffix...))
+}
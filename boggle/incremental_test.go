@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestIncrementalScore(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-common.txt")
+	freqs, err := frequencyCount(dictfile, ClassicRule.MinLength(), 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dice := append(append([]Die{}, diceSets["1992"]...), mustParseDice([]string{"QU TH IN ER HE AN", "AEIOU."})...)
+	for _, topology := range []Topology{GridTopology, TorusTopology, HexTopology} {
+		rows, cols := 3, 6
+		bs, err := newSolver(rows, cols, topology, dictfile, ClassicRule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rng := rand.New(rand.NewSource(3))
+		board := newDiceBoard(rng, rows, cols, dice)
+		is := bs.newIncremental(board, newScorer(bs))
+		if s := bs.score(board); is.Score() != s {
+			t.Fatalf("initial score %d, expected %d", is.Score(), s)
+		}
+
+		for i := 0; i < 200; i++ {
+			last := board.Clone()
+			lastScore := is.Score()
+			changed := board.DictShuffle(rng, bs.adjList, freqs)
+			if s, expected := is.update(board, changed), bs.score(board); s != expected {
+				t.Fatalf("%T: move %d changing %v scored %d, expected %d:\n%s", topology, i, changed, s, expected, board)
+			}
+			if rng.Intn(2) == 0 {
+				board = last.(*DiceBoard)
+				is.revert()
+				if is.Score() != lastScore {
+					t.Fatalf("%T: move %d reverted to %d, expected %d", topology, i, is.Score(), lastScore)
+				}
+			}
+		}
+
+		// The path counts must match a board counted from scratch
+		fresh := bs.newIncremental(board, newScorer(bs))
+		for w := range fresh.paths {
+			if fresh.paths[w] != is.paths[w] {
+				t.Fatalf("%T: word %d has %d paths, expected %d", topology, w, is.paths[w], fresh.paths[w])
+			}
+		}
+	}
+}
+
+func TestGADDAGWord(t *testing.T) {
+	for entry, expected := range map[string]string{"RAC[E": "CARE", "ERAC": "CARE", "C[ARE": "CARE"} {
+		if w := gaddagWord(entry); w != expected {
+			t.Errorf("entry %s is word %s, expected %s", entry, w, expected)
+		}
+	}
+}
+
+func BenchmarkIncrementalScore(b *testing.B) {
+	for _, n := range []int{4, 5, 6, 8} {
+		b.Run(fmt.Sprintf("%dx%d", n, n), func(b *testing.B) { benchmarkMoves(b, n, true) })
+	}
+}
+
+func BenchmarkFullScore(b *testing.B) {
+	for _, n := range []int{4, 5, 6, 8} {
+		b.Run(fmt.Sprintf("%dx%d", n, n), func(b *testing.B) { benchmarkMoves(b, n, false) })
+	}
+}
+
+// benchmarkMoves scores and rejects a stream of moves like those made by the optimizer
+func benchmarkMoves(b *testing.B, n int, incremental bool) {
+	dictfile := filepath.Join("dictionaries", "dictionary-enable1.txt")
+	bs, err := newSolver(n, n, GridTopology, dictfile, BigBoggleRule)
+	if err != nil {
+		b.Fatal(err)
+	}
+	freqs, err := frequencyCount(dictfile, BigBoggleRule.MinLength(), n*n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	dice := make([]Die, n*n)
+	for i := range dice {
+		dice[i] = diceSets["big"][i%len(diceSets["big"])]
+	}
+	board := newDiceBoard(rng, n, n, dice)
+	is := bs.newIncremental(board, newScorer(bs))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		last := board.Clone()
+		changed := board.DictShuffle(rng, bs.adjList, freqs)
+		if incremental {
+			is.update(board, changed)
+			is.revert()
+		} else {
+			bs.score(board)
+		}
+		board = last.(*DiceBoard)
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+var defaultDictionary = filepath.Join("dictionaries", "dictionary-enable1.txt")
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"solve", "list every word on the boards in the given files", runSolve},
+	{"optimize", "search for the highest-scoring board that can be rolled with a set of dice", runOptimize},
+	{"roll", "print a random board rolled from a set of dice", runRoll},
+	{"construct", "build a board on which every word in a list can be traced", runConstruct},
+	{"feasible", "check whether boards can be rolled with a set of dice and how likely they are", runFeasible},
+	{"maximize", "find a certified maximum-scoring board by branch and bound", runMaximize},
+	{"generate", "generate boards whose solutions fall inside a window of difficulty", runGenerate},
+	{"play", "play a timed round on a rolled board, checking each word you type", runPlay},
+	{"score", "score a multiplayer round, cancelling words found by more than one player", runScore},
+	{"render", "draw a board as an SVG or PNG image, with a word's path or a heatmap", runRender},
+	{"stats", "roll many boards and report the distribution of their scores and words", runStats},
+	{"serve", "serve solve, roll, and check requests over HTTP with dictionaries kept loaded", runServe},
+	{"compile", "compile a dictionary into a DAWG file that loads instantly", runCompile},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags] [arguments]\n\ncommands:\n", filepath.Base(os.Args[0]))
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nrun '%s <command> -h' for the flags of each command\n", filepath.Base(os.Args[0]))
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	if os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		usage()
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+// scoringFlags registers the flags that select a scoring rule and weight words by their metadata,
+// and returns a function that builds the rule
+func scoringFlags(fs *flag.FlagSet) func() (ScoringRule, error) {
+	name := fs.String("scoring", "classic", "scoring rule (classic, big, master, letters, or table)")
+	table := fs.String("scoring-table", "", "file of word lengths and points used by the table scoring rule")
+	info := newWordInfoFlags(fs)
+	return func() (ScoringRule, error) {
+		r, err := NewScoringRule(*name, *table)
+		if err != nil {
+			return nil, err
+		}
+		return info.rule(r)
+	}
+}
+
+// topologyFlag registers the flag that selects a board topology and returns a function that builds it
+func topologyFlag(fs *flag.FlagSet) func() (Topology, error) {
+	name := fs.String("topology", "grid", "board topology (grid, torus, hex, cube, or an adjacency file)")
+	return func() (Topology, error) {
+		return LoadTopology(*name)
+	}
+}
+
+// newRandom creates a random source from the given seed, using the clock if seed is zero.
+// The seed is logged so that a run can be reproduced.
+func newRandom(seed int64) (*rand.Rand, *splitMix) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Printf("seed: %d", seed)
+	return newSplitMixRand(seed)
+}
+
+func runSolve(args []string) error {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	asJSON := fs.Bool("json", false, "print the solution as JSON")
+	allPaths := fs.Bool("paths", false, "report every distinct path that spells each word")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: solve [flags] board-file...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("solve requires at least one board file")
+	}
+
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range fs.Args() {
+		board, err := ReadBoggleBoard(fn)
+		if err != nil {
+			return err
+		}
+		bs, err := newSolver(board.Rows(), board.Cols(), topo, *dictfile, r)
+		if err != nil {
+			return err
+		}
+
+		sol := bs.findWords(board, *allPaths)
+		if *asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(sol); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fmt.Printf("%s\n%s\n", fn, board)
+		for _, w := range sol.Words {
+			if *allPaths {
+				fmt.Printf("%s: %d\n", w.Word, w.Score)
+				for _, path := range w.Paths {
+					fmt.Printf("  %s\n", formatPath(path))
+				}
+			} else {
+				fmt.Printf("%s: %d %s\n", w.Word, w.Score, formatPath(w.Path))
+			}
+		}
+		fmt.Printf("total: %d\n\n", sol.Score)
+	}
+	return nil
+}
+
+// roller rolls boards of a fixed size
+type roller struct {
+	rows int
+	cols int
+	roll func(rng *rand.Rand) *BoggleBoard
+}
+
+// rollFlags registers the flags that select the dice to roll and returns a function that builds a roller for them
+func rollFlags(fs *flag.FlagSet) func() (roller, error) {
+	diceName := fs.String("dice", "1992", "dice set (1992, 1983, master, big, random, or a dice file)")
+	rows := fs.Int("rows", 4, "number of rows on a random board or a board rolled from a dice file")
+	cols := fs.Int("cols", 4, "number of columns on a random board or a board rolled from a dice file")
+	return func() (roller, error) {
+		return newRoller(*diceName, *rows, *cols)
+	}
+}
+
+// newRoller returns a roller for a built-in dice set, or for a dice file if there is no such set.
+// The size of the board is only used for random boards and dice files.
+func newRoller(diceName string, rows, cols int) (roller, error) {
+	switch strings.ToLower(diceName) {
+	case "1992":
+		return roller{4, 4, NewBoggleBoard}, nil
+	case "1983":
+		return roller{4, 4, NewBoggleBoard1983}, nil
+	case "master":
+		return roller{5, 5, NewBoggleBoardMaster}, nil
+	case "big":
+		return roller{5, 5, NewBoggleBoardBig}, nil
+	case "random":
+		return roller{rows, cols, func(rng *rand.Rand) *BoggleBoard { return NewBoggleBoardRandom(rng, rows, cols) }}, nil
+	}
+	dice, err := ReadDice(diceName)
+	if err != nil {
+		return roller{}, err
+	}
+	if err := ValidateDice(dice, rows, cols); err != nil {
+		return roller{}, fmt.Errorf("dice set %s: %v", diceName, err)
+	}
+	return roller{rows, cols, func(rng *rand.Rand) *BoggleBoard { return newBoggleBoard(rng, rows, cols, dice) }}, nil
+}
+
+func runRoll(args []string) error {
+	fs := flag.NewFlagSet("roll", flag.ExitOnError)
+	dice := rollFlags(fs)
+	seed := fs.Int64("seed", 0, "random seed (0 uses the clock)")
+	fs.Parse(args)
+
+	r, err := dice()
+	if err != nil {
+		return err
+	}
+	rng, _ := newRandom(*seed)
+
+	fmt.Println(r.roll(rng))
+	return nil
+}
+
+func runMaximize(args []string) error {
+	fs := flag.NewFlagSet("maximize", flag.ExitOnError)
+	rows := fs.Int("rows", 3, "number of rows on the board")
+	cols := fs.Int("cols", 3, "number of columns on the board")
+	tileList := fs.String("tiles", alphabet, "tiles that may be placed on the board, either one letter per tile or whitespace-separated tiles like \"A B Qu Th\"")
+	floor := fs.Int("floor", 0, "only report boards scoring at least this much (a known score prunes the search)")
+	report := fs.Duration("report", time.Minute, "interval between progress reports on stderr")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "number of assignments of tile buckets to search concurrently")
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Parse(args)
+
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	tiles, err := ParseDie(*tileList)
+	if err != nil {
+		return err
+	}
+	for _, t := range tiles {
+		if t == "" {
+			return fmt.Errorf("blank tiles cannot be placed on the board")
+		}
+	}
+	if len(tiles) > maxBoundTiles || *rows**cols > maxBoundTiles {
+		return fmt.Errorf("at most %d tiles and %d cells can be searched", maxBoundTiles, maxBoundTiles)
+	}
+	if *report <= 0 {
+		return fmt.Errorf("report interval must be positive")
+	}
+	if *workers < 1 {
+		return fmt.Errorf("need at least one worker")
+	}
+
+	bs, err := newSolver(*rows, *cols, topo, *dictfile, r)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	last := start
+	result := bs.maximize(tiles, *floor, *workers, func(p boundProgress) {
+		if now := time.Now(); now.Sub(last) >= *report {
+			last = now
+			log.Printf("%d of %d assignments of tile buckets searched (%.1f%%) in %v, best score %d, %d partial boards", p.Classes, p.Total, 100*float64(p.Classes)/float64(p.Total), now.Sub(start).Round(time.Second), p.Best, p.Nodes)
+		}
+	})
+	if result.Board == nil {
+		fmt.Printf("no board scores at least %d (%d partial boards searched)\n", *floor, result.Nodes)
+		return nil
+	}
+	fmt.Printf("%s\nscore: %d (certified maximum, %d partial boards searched)\n", result.Board, result.Score, result.Nodes)
+	return nil
+}
+
+func runCompile(args []string) error {
+	fs := flag.NewFlagSet("compile", flag.ExitOnError)
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	out := fs.String("o", "", "DAWG file to write (defaults to the dictionary file with a .dawg extension)")
+	translit := fs.Bool("transliterate", true, "spell accented letters in ASCII instead of dropping words containing them")
+	strip := fs.Bool("strip-punctuation", false, "remove hyphens and apostrophes instead of dropping words containing them")
+	minLength := fs.Int("min-length", 0, "drop words shorter than this")
+	maxLength := fs.Int("max-length", 0, "drop words longer than this (0 for no limit)")
+	allow := fs.String("allow", "", "file listing the only words to keep")
+	block := fs.String("block", "", "file listing words to drop")
+	fs.Parse(args)
+
+	if *out == "" {
+		*out = strings.TrimSuffix(*dictfile, filepath.Ext(*dictfile)) + ".dawg"
+	}
+	opts := wordListOptions{Transliterate: *translit, StripPunctuation: *strip, MinLength: *minLength, MaxLength: *maxLength}
+	var err error
+	if *allow != "" {
+		if opts.Allow, err = readWordSet(*allow); err != nil {
+			return err
+		}
+	}
+	if *block != "" {
+		if opts.Block, err = readWordSet(*block); err != nil {
+			return err
+		}
+	}
+	words, stats, err := readWordListFile(*dictfile, opts)
+	if err != nil {
+		return err
+	}
+	log.Printf("%s: %s", *dictfile, stats)
+	d, err := BuildDAWG(words)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if _, err := d.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	log.Printf("%s: %d words in %d edges", *out, d.Words(), len(d.edges)-1)
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// PlayerWords is the list of words written down by one player
+type PlayerWords struct {
+	Name  string
+	Words []string
+}
+
+// PlayerResult is one player's outcome in a multiplayer round
+type PlayerResult struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+	// Words are the valid words found by no other player, which are the only ones that score
+	Words []WordResult `json:"words"`
+	// Cancelled are the valid words also found by another player
+	Cancelled []string `json:"cancelled"`
+	// Rejected are the words that are not valid, with the reason for each
+	Rejected []RejectedWord `json:"rejected"`
+}
+
+// RejectedWord is a word that does not count and the reason why
+type RejectedWord struct {
+	Word   string `json:"word"`
+	Reason string `json:"reason"`
+}
+
+// scorePlayers scores a multiplayer round under the official rules: each player's words are checked against
+// the board and dictionary, and every valid word found by more than one player is crossed off all of their lists.
+// A word written more than once by the same player counts once.  Results are in the same order as the players.
+func (bs *boggleSolver) scorePlayers(bb Boggler, players []PlayerWords) []PlayerResult {
+	results := make([]PlayerResult, len(players))
+	valid := make([][]WordResult, len(players))
+	finders := make(map[string]int)
+
+	for i, p := range players {
+		results[i] = PlayerResult{Name: p.Name, Words: []WordResult{}, Cancelled: []string{}, Rejected: []RejectedWord{}}
+		seen := make(map[string]bool)
+		for _, text := range p.Words {
+			word := strings.ToUpper(strings.TrimSpace(text))
+			if word == "" {
+				continue
+			}
+			if seen[word] {
+				results[i].Rejected = append(results[i].Rejected, RejectedWord{Word: word, Reason: "written more than once"})
+				continue
+			}
+			seen[word] = true
+			w, err := bs.checkWord(bb, word)
+			if err != nil {
+				results[i].Rejected = append(results[i].Rejected, RejectedWord{Word: word, Reason: rejectReason(err)})
+				continue
+			}
+			valid[i] = append(valid[i], w)
+			finders[word]++
+		}
+	}
+
+	for i := range players {
+		for _, w := range valid[i] {
+			if finders[w.Word] > 1 {
+				results[i].Cancelled = append(results[i].Cancelled, w.Word)
+				continue
+			}
+			results[i].Words = append(results[i].Words, w)
+			results[i].Score += w.Score
+		}
+	}
+	return results
+}
+
+// readPlayerWords reads one player's words, which may be separated by any whitespace
+func readPlayerWords(name string, r io.Reader) (PlayerWords, error) {
+	p := PlayerWords{Name: name}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		p.Words = append(p.Words, scanner.Text())
+	}
+	return p, scanner.Err()
+}
+
+// readPlayerLines reads every player's words from one stream, with one player per line written as "name: word word ..."
+func readPlayerLines(r io.Reader) ([]PlayerWords, error) {
+	var players []PlayerWords
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.SplitN(text, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("line %d: expected a player's name, a colon, and their words", line)
+		}
+		players = append(players, PlayerWords{Name: strings.TrimSpace(parts[0]), Words: strings.Fields(parts[1])})
+	}
+	return players, scanner.Err()
+}
+
+// writePlayerTable writes the score table followed by each player's cancelled and rejected words
+func writePlayerTable(w io.Writer, results []PlayerResult) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "player\tscore\twords\tcancelled\trejected\n")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", r.Name, r.Score, len(r.Words), len(r.Cancelled), len(r.Rejected))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if len(r.Cancelled) > 0 {
+			fmt.Fprintf(w, "\n%s cancelled: %s\n", r.Name, strings.Join(r.Cancelled, " "))
+		}
+		if len(r.Rejected) > 0 {
+			fmt.Fprintf(w, "\n%s rejected:\n", r.Name)
+			for _, rej := range r.Rejected {
+				fmt.Fprintf(w, "  %s: %s\n", rej.Word, rej.Reason)
+			}
+		}
+	}
+	return nil
+}
+
+func runScore(args []string) error {
+	fs := flag.NewFlagSet("score", flag.ExitOnError)
+	boardFile := fs.String("board", "", "board file the round was played on")
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: score -board board-file [flags] [word-file...]\n\n")
+		fmt.Fprintf(fs.Output(), "Each word file holds one player's words, and the player is named after the file; '-' reads a player from standard input.\n")
+		fmt.Fprintf(fs.Output(), "With no word files, standard input holds one player per line as \"name: word word ...\".\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *boardFile == "" {
+		fs.Usage()
+		return errors.New("score requires a board file")
+	}
+
+	board, err := ReadBoggleBoard(*boardFile)
+	if err != nil {
+		return err
+	}
+	var players []PlayerWords
+	if fs.NArg() == 0 {
+		if players, err = readPlayerLines(os.Stdin); err != nil {
+			return fmt.Errorf("standard input: %v", err)
+		}
+	}
+	for _, fn := range fs.Args() {
+		var p PlayerWords
+		if fn == "-" {
+			p, err = readPlayerWords("stdin", os.Stdin)
+		} else {
+			var file *os.File
+			if file, err = os.Open(fn); err != nil {
+				return err
+			}
+			p, err = readPlayerWords(strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn)), file)
+			file.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", fn, err)
+		}
+		players = append(players, p)
+	}
+
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	bs, err := newSolver(board.Rows(), board.Cols(), topo, *dictfile, r)
+	if err != nil {
+		return err
+	}
+
+	results := bs.scorePlayers(board, players)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	return writePlayerTable(os.Stdout, results)
+}
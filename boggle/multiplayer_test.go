@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScorePlayers(t *testing.T) {
+	board, err := ReadBoggleBoard(filepath.Join("test", "board-points100.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(board.Rows(), board.Cols(), GridTopology, filepath.Join("dictionaries", "dictionary-yawl.txt"), ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	players, err := readPlayerLines(strings.NewReader("# round one\nalice: elf fer ox elf\n\nbob: ELF helm\ncarol: fer\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := bs.scorePlayers(board, players)
+	if len(results) != 3 {
+		t.Fatalf("%d results, expected 3", len(results))
+	}
+
+	alice, bob, carol := results[0], results[1], results[2]
+	if alice.Score != 0 || len(alice.Words) != 0 || !reflect.DeepEqual(alice.Cancelled, []string{"ELF", "FER"}) {
+		t.Errorf("alice: %+v", alice)
+	}
+	expected := []RejectedWord{{"OX", "too short, words need at least 3 letters"}, {"ELF", "written more than once"}}
+	if !reflect.DeepEqual(alice.Rejected, expected) {
+		t.Errorf("alice rejected %v, expected %v", alice.Rejected, expected)
+	}
+	if bob.Score != 0 || !reflect.DeepEqual(bob.Cancelled, []string{"ELF"}) || !reflect.DeepEqual(bob.Rejected, []RejectedWord{{"HELM", "not on the board"}}) {
+		t.Errorf("bob: %+v", bob)
+	}
+	if !reflect.DeepEqual(carol.Cancelled, []string{"FER"}) {
+		t.Errorf("carol: %+v", carol)
+	}
+
+	// Without competition, every valid word scores
+	solo := bs.scorePlayers(board, []PlayerWords{{Name: "dave", Words: []string{"elf", "fer"}}})[0]
+	if solo.Score != 2 || len(solo.Words) != 2 {
+		t.Errorf("dave: %+v", solo)
+	}
+
+	var buf bytes.Buffer
+	if err := writePlayerTable(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "alice cancelled: ELF FER") || !strings.Contains(buf.String(), "  HELM: not on the board") {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestReadPlayerLines(t *testing.T) {
+	if _, err := readPlayerLines(strings.NewReader("no colon here\n")); err == nil {
+		t.Error("read a line without a player name")
+	}
+	p, err := readPlayerWords("erin", strings.NewReader("one two\n three\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "erin" || !reflect.DeepEqual(p.Words, []string{"one", "two", "three"}) {
+		t.Errorf("read %+v", p)
+	}
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// searchFlags are the optimize flags that describe the search itself.
+// They are saved in checkpoints and restored on resume, overriding the command line.
+var searchFlags = []string{
+	"rows", "cols", "dice", "dict", "method", "restart", "workers",
+	"temps", "swap", "scoring", "scoring-table", "topology",
+	"word-info", "tier", "only", "exclude", "rare-below", "weight",
+	"population", "elite", "tournament", "crossover", "crossover-rate", "mutation-rate",
+}
+
+// runControl decides when an optimization run stops, reports, and saves its state
+type runControl struct {
+	stop <-chan time.Time
+	// rounds is the number of restarts, tempering rounds, or generations to run, or zero to run until stopped
+	rounds int
+	report time.Duration
+	saver  *checkpointer
+	// resume is the checkpoint to continue from, or nil to start fresh
+	resume *checkpoint
+}
+
+func runOptimize(args []string) error {
+	fs := flag.NewFlagSet("optimize", flag.ExitOnError)
+	rows := fs.Int("rows", 4, "number of rows on the board")
+	cols := fs.Int("cols", 4, "number of columns on the board")
+	diceName := fs.String("dice", "1992", "dice set (1992, 1983, master, big, or a dice file)")
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	method := fs.String("method", "restart", "optimization method (restart, tempering, or genetic)")
+	duration := fs.Duration("duration", 0, "time to run before stopping (0 runs forever)")
+	restart := fs.Int("restart", 1000000, "steps between restarting a worker from a fresh board, each worker in turn (restart method)")
+	seed := fs.Int64("seed", 0, "random seed (0 uses the clock)")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "number of concurrent workers (restart and genetic methods) or replicas (tempering method)")
+	temps := fs.String("temps", "", "comma-separated temperature ladder, coldest first (tempering method, overrides -tmin, -tmax, and -workers)")
+	tmin := fs.Float64("tmin", 1, "coldest temperature of a geometric ladder (tempering method)")
+	tmax := fs.Float64("tmax", 200, "hottest temperature of a geometric ladder (tempering method)")
+	swap := fs.Int("swap", 100, "steps each replica takes between swap attempts (tempering method)")
+	rounds := fs.Int("rounds", 0, "number of restarts, rounds, or generations to run before stopping, 0 to run until -duration")
+	population := fs.Int("population", 200, "number of boards in each generation (genetic method)")
+	elite := fs.Int("elite", 4, "number of best boards kept unchanged in each generation (genetic method)")
+	tournament := fs.Int("tournament", 3, "number of boards drawn to choose each parent (genetic method)")
+	crossoverOps := fs.String("crossover", "rows,quadrants,regions", "comma-separated crossover operators to choose from: rows, quadrants, or regions (genetic method)")
+	crossoverRate := fs.Float64("crossover-rate", 0.8, "chance a child is bred from two parents rather than copied from one (genetic method)")
+	mutationRate := fs.Float64("mutation-rate", 0.5, "chance a child is mutated by re-rolling dice (genetic method)")
+	cacheSize := fs.Int("cache", 1<<20, "number of board scores to remember, so that boards and their rotations and reflections are not scored twice (0 remembers none)")
+	report := fs.Duration("report", time.Minute, "interval between acceptance rate reports on stderr (tempering method)")
+	checkpointFile := fs.String("checkpoint", "", "file to save the optimizer state to periodically and when stopping (defaults to the -resume file)")
+	checkpointEvery := fs.Duration("checkpoint-every", 10*time.Minute, "interval between checkpoints")
+	resume := fs.String("resume", "", "checkpoint file to resume from; the board, dice, dictionary, scoring, and method settings are taken from it")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Parse(args)
+
+	ctl := runControl{rounds: *rounds, report: *report}
+	if *resume != "" {
+		cp, err := readCheckpoint(*resume)
+		if err != nil {
+			return err
+		}
+		for name, value := range cp.Flags {
+			if err := fs.Set(name, value); err != nil {
+				return fmt.Errorf("%s: flag %s: %v", *resume, name, err)
+			}
+		}
+		ctl.resume = cp
+		if *checkpointFile == "" {
+			*checkpointFile = *resume
+		}
+	}
+
+	if *report <= 0 {
+		return fmt.Errorf("report interval must be positive")
+	}
+	if *checkpointEvery <= 0 {
+		return fmt.Errorf("checkpoint interval must be positive")
+	}
+
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	if _, err := topo.AdjList(*rows, *cols); err != nil {
+		return err
+	}
+	var dice []Die
+	if ctl.resume != nil {
+		dice = ctl.resume.Dice
+	} else if dice, err = LoadDice(*diceName); err != nil {
+		return err
+	}
+	if err := ValidateDice(dice, *rows, *cols); err != nil {
+		return fmt.Errorf("dice set %s: %v", *diceName, err)
+	}
+	rng, _ := newRandom(*seed)
+
+	opts := optimizeOptions{rows: *rows, cols: *cols, topology: topo, dice: dice, dictfile: *dictfile, rule: r, cacheSize: *cacheSize}
+
+	if *duration > 0 {
+		ctl.stop = time.After(*duration)
+	}
+
+	var ladder []float64
+	switch *method {
+	case "restart":
+		if *restart < 1 {
+			return fmt.Errorf("restart interval must be at least one step")
+		}
+	case "tempering":
+		if ctl.resume != nil {
+			break
+		}
+		if *temps != "" {
+			ladder, err = parseLadder(*temps)
+		} else {
+			ladder, err = geometricLadder(*tmin, *tmax, *workers)
+		}
+		if err != nil {
+			return err
+		}
+		if *swap < 1 {
+			return fmt.Errorf("swap interval must be at least one step")
+		}
+		fs.Set("temps", formatLadder(ladder))
+	case "genetic":
+	default:
+		return fmt.Errorf("unknown optimization method %q", *method)
+	}
+	params := geneticParams{
+		population:    *population,
+		elite:         *elite,
+		tournament:    *tournament,
+		crossoverRate: *crossoverRate,
+		mutationRate:  *mutationRate,
+		workers:       *workers,
+	}
+	if *method == "genetic" {
+		if params.crossovers, err = parseCrossovers(*crossoverOps); err != nil {
+			return err
+		}
+		if err := params.validate(); err != nil {
+			return err
+		}
+	}
+
+	if *checkpointFile != "" {
+		saver := &checkpointer{filename: *checkpointFile, flags: make(map[string]string), dice: dice}
+		for _, name := range searchFlags {
+			saver.flags[name] = fs.Lookup(name).Value.String()
+		}
+		ticker := time.NewTicker(*checkpointEvery)
+		defer ticker.Stop()
+		saver.tick = ticker.C
+		ctl.saver = saver
+	}
+
+	switch *method {
+	case "restart":
+		return optimizeRestart(rng.Int63(), opts, *workers, *restart, ctl)
+	case "genetic":
+		return optimizeGenetic(rng.Int63(), opts, params, ctl)
+	}
+	return optimizeTempering(rng.Int63(), opts, ladder, *swap, ctl)
+}
+
+// printProgress writes a line of CSV progress: restart, round, or generation number, milliseconds since start, score, and board
+func printProgress(i int, start time.Time, score int, board []string) {
+	fmt.Printf("%d,%d,%d,%s\n", i, time.Since(start).Milliseconds(), score, strings.Join(board, ","))
+}
+
+// optimizeRestart runs independent hill-climbing workers until stopped or until the requested number of restarts
+// is complete, restarting one worker from a fresh board every restart steps.  Like tempering, a run with a fixed
+// number of restarts is exactly reproducible from its seed.
+func optimizeRestart(seed int64, opts optimizeOptions, workers int, restart int, ctl runControl) error {
+	bs, err := newSolver(opts.rows, opts.cols, opts.topology, opts.dictfile, opts.rule)
+	if err != nil {
+		return err
+	}
+	freqs, err := frequencyCount(opts.dictfile, opts.rule.MinLength(), opts.rows*opts.cols)
+	if err != nil {
+		return err
+	}
+
+	var r *restarting
+	start := time.Now()
+	if cp := ctl.resume; cp != nil {
+		if r, err = restoreRestarting(cp, bs, freqs, opts, workers, restart); err != nil {
+			return err
+		}
+		start = start.Add(-cp.Elapsed)
+	} else {
+		r = newRestarting(seed, bs, freqs, opts, workers, restart)
+		printProgress(0, start, r.best, r.bestBoard)
+	}
+	defer reportCache(r.cache)
+
+	var tick <-chan time.Time
+	if ctl.saver != nil {
+		tick = ctl.saver.tick
+	}
+	checkpoint := func() error {
+		cp := r.checkpoint()
+		cp.Elapsed = time.Since(start)
+		return ctl.saver.save(cp)
+	}
+
+	first := r.restarts
+	for ctl.rounds <= 0 || r.restarts < first+ctl.rounds {
+		// Progress lines number the restarts, so a board found before the first restart is on line 0
+		i := r.restarts
+		if r.round() {
+			printProgress(i, start, r.best, r.bestBoard)
+		}
+		select {
+		case <-ctl.stop:
+			return checkpoint()
+		case <-tick:
+			if err := checkpoint(); err != nil {
+				return err
+			}
+		default:
+		}
+	}
+	return checkpoint()
+}
+
+// optimizeTempering runs parallel tempering until stopped or until the requested number of rounds is complete.
+// A run with a fixed number of rounds is exactly reproducible from its seed, even when interrupted and resumed.
+func optimizeTempering(seed int64, opts optimizeOptions, ladder []float64, steps int, ctl runControl) error {
+	bs, err := newSolver(opts.rows, opts.cols, opts.topology, opts.dictfile, opts.rule)
+	if err != nil {
+		return err
+	}
+	freqs, err := frequencyCount(opts.dictfile, opts.rule.MinLength(), opts.rows*opts.cols)
+	if err != nil {
+		return err
+	}
+
+	var t *tempering
+	first := 1
+	start := time.Now()
+	if cp := ctl.resume; cp != nil {
+		if t, err = restoreTempering(cp, bs, freqs, opts, steps); err != nil {
+			return err
+		}
+		first = cp.Round + 1
+		start = start.Add(-cp.Elapsed)
+	} else {
+		t = newTempering(seed, bs, freqs, opts, ladder, steps)
+		printProgress(0, start, t.best, t.bestBoard)
+	}
+
+	reporter := time.NewTicker(ctl.report)
+	defer reporter.Stop()
+	defer reportAcceptance(t)
+
+	var tick <-chan time.Time
+	if ctl.saver != nil {
+		tick = ctl.saver.tick
+	}
+	checkpoint := func(i int) error {
+		cp := t.checkpoint()
+		cp.Round = i
+		cp.Elapsed = time.Since(start)
+		return ctl.saver.save(cp)
+	}
+
+	i := first
+	for ; ctl.rounds <= 0 || i < first+ctl.rounds; i++ {
+		if t.round() {
+			printProgress(i, start, t.best, t.bestBoard)
+		}
+		select {
+		case <-ctl.stop:
+			return checkpoint(i)
+		case <-tick:
+			if err := checkpoint(i); err != nil {
+				return err
+			}
+		case <-reporter.C:
+			reportAcceptance(t)
+		default:
+		}
+	}
+	return checkpoint(i - 1)
+}
+
+// optimizeGenetic evolves a population of boards until stopped or until the requested number of generations is complete.
+// Like tempering, a run with a fixed number of generations is exactly reproducible from its seed.
+func optimizeGenetic(seed int64, opts optimizeOptions, params geneticParams, ctl runControl) error {
+	bs, err := newSolver(opts.rows, opts.cols, opts.topology, opts.dictfile, opts.rule)
+	if err != nil {
+		return err
+	}
+	freqs, err := frequencyCount(opts.dictfile, opts.rule.MinLength(), opts.rows*opts.cols)
+	if err != nil {
+		return err
+	}
+
+	var g *genetic
+	first := 1
+	start := time.Now()
+	if cp := ctl.resume; cp != nil {
+		if g, err = restoreGenetic(cp, bs, freqs, opts, params); err != nil {
+			return err
+		}
+		first = cp.Round + 1
+		start = start.Add(-cp.Elapsed)
+	} else {
+		g = newGenetic(seed, bs, freqs, opts, params)
+		printProgress(0, start, g.best, g.bestBoard)
+	}
+	defer reportCache(g.cache)
+
+	var tick <-chan time.Time
+	if ctl.saver != nil {
+		tick = ctl.saver.tick
+	}
+	checkpoint := func(i int) error {
+		cp := g.checkpoint()
+		cp.Round = i
+		cp.Elapsed = time.Since(start)
+		return ctl.saver.save(cp)
+	}
+
+	i := first
+	for ; ctl.rounds <= 0 || i < first+ctl.rounds; i++ {
+		if g.generation() {
+			printProgress(i, start, g.best, g.bestBoard)
+		}
+		select {
+		case <-ctl.stop:
+			return checkpoint(i)
+		case <-tick:
+			if err := checkpoint(i); err != nil {
+				return err
+			}
+		default:
+		}
+	}
+	return checkpoint(i - 1)
+}
+
+// reportAcceptance logs the acceptance rates of a tempering run to stderr
+func reportAcceptance(t *tempering) {
+	moves, swaps := t.acceptance()
+	for i, r := range t.replicas {
+		if i < len(swaps) {
+			log.Printf("T=%.4g: score %d, moves accepted %.1f%%, swaps with T=%.4g accepted %.1f%%", r.temperature, r.score, 100*moves[i], t.replicas[i+1].temperature, 100*swaps[i])
+		} else {
+			log.Printf("T=%.4g: score %d, moves accepted %.1f%%", r.temperature, r.score, 100*moves[i])
+		}
+	}
+	reportCache(t.cache)
+}
+
+// reportCache logs how often the score cache held the score of a board
+func reportCache(c *scoreCache) {
+	if c != nil {
+		log.Printf("score cache: %.1f%% of boards already scored, counting %d symmetries", 100*c.hitRate(), len(c.canon.perms))
+	}
+}
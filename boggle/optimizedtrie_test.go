@@ -12,7 +12,7 @@ type node struct {
 	next [radix]*node
 }
 
-// OptimizedTrie an optimized symbol table trie for Boggle
+// OptimizedTrie an optimized symbol table trie for Boggle, kept for the reference solver the tests check against
 type OptimizedTrie struct {
 	root *node
 }
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// game is a single round of Boggle played against the solver
+type game struct {
+	solver   *boggleSolver
+	board    Boggler
+	solution *Solution
+	found    []WordResult
+	score    int
+}
+
+func newGame(bs *boggleSolver, board Boggler) *game {
+	return &game{solver: bs, board: board, solution: bs.findWords(board, false)}
+}
+
+// guess checks a word typed by the player and records it if it scores
+func (g *game) guess(text string) (WordResult, error) {
+	word := strings.ToUpper(strings.TrimSpace(text))
+	if word == "" {
+		return WordResult{}, errors.New("no word given")
+	}
+	for _, f := range g.found {
+		if f.Word == word {
+			return WordResult{}, &wordError{Word: word, Reason: "already found"}
+		}
+	}
+	w, err := g.solver.checkWord(g.board, word)
+	if err != nil {
+		return WordResult{}, err
+	}
+	g.found = append(g.found, w)
+	g.score += w.Score
+	return w, nil
+}
+
+// wordError explains why a word does not count
+type wordError struct {
+	Word   string
+	Reason string
+}
+
+func (e *wordError) Error() string {
+	return e.Word + ": " + e.Reason
+}
+
+// rejectReason returns why a word was rejected, from a wordError or any other error
+func rejectReason(err error) string {
+	var we *wordError
+	if errors.As(err, &we) {
+		return we.Reason
+	}
+	return err.Error()
+}
+
+// checkWord checks that an uppercase word is long enough, can be spelled on the board, and scores in the dictionary.
+// Errors are always *wordError.
+func (bs *boggleSolver) checkWord(bb Boggler, word string) (WordResult, error) {
+	if len(word) < bs.rule.MinLength() {
+		return WordResult{}, &wordError{Word: word, Reason: fmt.Sprintf("too short, words need at least %d letters", bs.rule.MinLength())}
+	}
+	path := bs.findPath(bb, word)
+	if path == nil {
+		return WordResult{}, &wordError{Word: word, Reason: "not on the board"}
+	}
+	score := bs.dictionary.Get(word)
+	if score == 0 {
+		// Words can be in the dictionary yet score nothing, such as words excluded by their metadata
+		if bs.dictionary.Has(word) {
+			return WordResult{}, &wordError{Word: word, Reason: "does not score under the chosen rules"}
+		}
+		return WordResult{}, &wordError{Word: word, Reason: "not in the dictionary"}
+	}
+	return WordResult{Word: word, Score: score, Path: path}, nil
+}
+
+// missed returns the words on the board that the player did not find, in alphabetical order
+func (g *game) missed() []WordResult {
+	found := make(map[string]bool)
+	for _, f := range g.found {
+		found[f.Word] = true
+	}
+	var missed []WordResult
+	for _, w := range g.solution.Words {
+		if !found[w.Word] {
+			missed = append(missed, w)
+		}
+	}
+	return missed
+}
+
+// findPath returns a path of adjacent, unrepeated cells spelling word, or nil if there is none.
+// Unlike the solver, it does not consult the dictionary.
+func (bs *boggleSolver) findPath(bb Boggler, word string) []Cell {
+	visited := make([]bool, len(bs.adjList))
+	var path []int
+	var search func(p int, rest string) bool
+	search = func(p int, rest string) bool {
+		tile := bb.GetLinear(p)
+		if visited[p] || tile == "" || !strings.HasPrefix(rest, tile) {
+			return false
+		}
+		visited[p] = true
+		path = append(path, p)
+		rest = rest[len(tile):]
+		if rest == "" {
+			return true
+		}
+		for _, p2 := range bs.adjList[p] {
+			if search(p2, rest) {
+				return true
+			}
+		}
+		visited[p] = false
+		path = path[:len(path)-1]
+		return false
+	}
+
+	for p := range bs.adjList {
+		if search(p, word) {
+			cells := make([]Cell, len(path))
+			for i, c := range path {
+				cells[i] = Cell{Row: c / bs.cols, Col: c % bs.cols}
+			}
+			return cells
+		}
+	}
+	return nil
+}
+
+// play reads guesses from in until the player stops or the round ends, then reports the results.
+// A blank line shows the board and the time left again.
+func (g *game) play(in io.Reader, out io.Writer, end time.Time, timer <-chan time.Time, missed int) {
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	fmt.Fprintf(out, "%s\nFind as many words as you can in %s.  Enter one word per line, or a blank line to see the board again.\n", g.board, formatRemaining(time.Until(end)))
+	for playing := true; playing; {
+		select {
+		case <-timer:
+			fmt.Fprintf(out, "\nTime's up!\n")
+			playing = false
+		case text, ok := <-lines:
+			if !ok {
+				playing = false
+				break
+			}
+			if strings.TrimSpace(text) == "" {
+				fmt.Fprintf(out, "%s\n%s left\n", g.board, formatRemaining(time.Until(end)))
+				break
+			}
+			w, err := g.guess(text)
+			if err != nil {
+				fmt.Fprintf(out, "%v\n", err)
+				break
+			}
+			fmt.Fprintf(out, "%s: %d (total %d, %s left)\n", w.Word, w.Score, g.score, formatRemaining(time.Until(end)))
+		}
+	}
+	g.report(out, missed)
+}
+
+// report shows the words the player found and the ones they missed
+func (g *game) report(out io.Writer, missed int) {
+	fmt.Fprintf(out, "\nYou found %d of %d words, scoring %d of %d points.\n", len(g.found), len(g.solution.Words), g.score, g.solution.Score)
+	for _, f := range g.found {
+		fmt.Fprintf(out, "  %s: %d\n", f.Word, f.Score)
+	}
+	m := g.missed()
+	if len(m) == 0 || missed == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Missed:\n")
+	for i, w := range m {
+		if missed > 0 && i == missed {
+			fmt.Fprintf(out, "  ... and %d more\n", len(m)-missed)
+			break
+		}
+		fmt.Fprintf(out, "  %s: %d\n", w.Word, w.Score)
+	}
+}
+
+// formatRemaining writes a duration as minutes and seconds
+func formatRemaining(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	s := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+func runPlay(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	dice := rollFlags(fs)
+	boardFile := fs.String("board", "", "board file to play instead of rolling the dice")
+	limit := fs.Duration("time", 3*time.Minute, "length of the round")
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	seed := fs.Int64("seed", 0, "random seed (0 uses the clock)")
+	missed := fs.Int("missed", -1, "number of missed words to show at the end (-1 shows them all)")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Parse(args)
+
+	var board *BoggleBoard
+	if *boardFile != "" {
+		var err error
+		if board, err = ReadBoggleBoard(*boardFile); err != nil {
+			return err
+		}
+	} else {
+		ro, err := dice()
+		if err != nil {
+			return err
+		}
+		rng, _ := newRandom(*seed)
+		board = ro.roll(rng)
+	}
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	bs, err := newSolver(board.Rows(), board.Cols(), topo, *dictfile, r)
+	if err != nil {
+		return err
+	}
+
+	g := newGame(bs, board)
+	end := time.Now().Add(*limit)
+	timer := time.NewTimer(*limit)
+	defer timer.Stop()
+	g.play(os.Stdin, os.Stdout, end, timer.C, *missed)
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindPath(t *testing.T) {
+	var board BoggleBoard
+	if err := board.UnmarshalText([]byte("2 3\nQu I T\nE . S")); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(2, 3, GridTopology, filepath.Join("dictionaries", "dictionary-common.txt"), ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := bs.findPath(&board, "QUITS")
+	expected := []Cell{{0, 0}, {0, 1}, {0, 2}, {1, 2}}
+	if !reflect.DeepEqual(path, expected) {
+		t.Errorf("path %v, expected %v", path, expected)
+	}
+	for _, w := range []string{"QUIQU", "QIT", "SE", "TEQU"} {
+		if path := bs.findPath(&board, w); path != nil {
+			t.Errorf("found path %v for %s", path, w)
+		}
+	}
+	if path := bs.findPath(&board, "TIQUE"); len(path) != 4 {
+		t.Errorf("path %v for TIQUE, expected 4 cells", path)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	board, err := ReadBoggleBoard(filepath.Join("test", "board-points100.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(board.Rows(), board.Cols(), GridTopology, filepath.Join("dictionaries", "dictionary-yawl.txt"), ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := newGame(bs, board)
+	if g.solution.Score != bs.score(board) {
+		t.Fatalf("solution scores %d, expected %d", g.solution.Score, bs.score(board))
+	}
+
+	// Guess the first two words of the solution, one of them twice, and some that do not count
+	first, second := g.solution.Words[0].Word, g.solution.Words[1].Word
+	input := strings.Join([]string{strings.ToLower(first), "", first, "xq", "zzzzzz", second}, "\n")
+	var out bytes.Buffer
+	g.play(strings.NewReader(input), &out, time.Now().Add(time.Minute), nil, 3)
+
+	if len(g.found) != 2 || g.score != g.solution.Words[0].Score+g.solution.Words[1].Score {
+		t.Errorf("found %v scoring %d", g.found, g.score)
+	}
+	text := out.String()
+	for _, msg := range []string{first + ": already found", "XQ: too short", "ZZZZZZ: not on the board", "You found 2 of", "... and"} {
+		if !strings.Contains(text, msg) {
+			t.Errorf("output does not contain %q:\n%s", msg, text)
+		}
+	}
+	if len(g.missed()) != len(g.solution.Words)-2 {
+		t.Errorf("%d words missed, expected %d", len(g.missed()), len(g.solution.Words)-2)
+	}
+}
+
+func TestPlayTimer(t *testing.T) {
+	board, err := ReadBoggleBoard(filepath.Join("test", "board-points100.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(board.Rows(), board.Cols(), GridTopology, filepath.Join("dictionaries", "dictionary-yawl.txt"), ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := newGame(bs, board)
+
+	// The player never types anything, so only the timer can end the round
+	timer := make(chan time.Time, 1)
+	timer <- time.Now()
+	var out bytes.Buffer
+	blocked := &blockingReader{done: make(chan struct{})}
+	defer close(blocked.done)
+	g.play(blocked, &out, time.Now(), timer, 0)
+	if !strings.Contains(out.String(), "Time's up!") || strings.Contains(out.String(), "Missed") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+// blockingReader never returns any input until done is closed
+type blockingReader struct {
+	done chan struct{}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	<-r.done
+	return 0, nil
+}
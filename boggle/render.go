@@ -0,0 +1,424 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// boardImage draws a board as SVG or PNG, optionally with the path of one word or a heatmap of how many words use each cell
+type boardImage struct {
+	board Boggler
+	// cellSize is the width and height of each cell in pixels
+	cellSize int
+	// hex shifts odd rows half a cell to the right, as in HexTopology
+	hex bool
+	// word and path are the word whose path is drawn, if any
+	word string
+	path []Cell
+	// heat counts the words using each cell, if a heatmap is drawn
+	heat []int
+}
+
+// The colors of plot.py and paths.py
+var (
+	tileColor    = color.RGBA{0xf4, 0xef, 0xe4, 0xff}
+	blockedColor = color.RGBA{0x6c, 0x6f, 0x70, 0xff}
+	heatColor    = color.RGBA{0xef, 0x82, 0x00, 0xff}
+	pathColor    = color.RGBA{0xc0, 0x51, 0x31, 0xff}
+	textColor    = color.RGBA{0x20, 0x20, 0x20, 0xff}
+)
+
+func newBoardImage(bb Boggler, cellSize int) *boardImage {
+	return &boardImage{board: bb, cellSize: cellSize}
+}
+
+// overlayWord draws the path of a word in the solution of the board
+func (im *boardImage) overlayWord(sol *Solution, word string) error {
+	word = strings.ToUpper(word)
+	for _, w := range sol.Words {
+		if w.Word == word {
+			im.word, im.path = w.Word, w.Path
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not on the board", word)
+}
+
+// overlayHeatmap shades each cell by the number of words in the solution that use it.
+// A word counts once per cell, however many of its paths pass through the cell.
+func (im *boardImage) overlayHeatmap(sol *Solution) {
+	cols := im.board.Cols()
+	im.heat = make([]int, im.board.Rows()*cols)
+	for _, w := range sol.Words {
+		paths := w.Paths
+		if paths == nil {
+			paths = [][]Cell{w.Path}
+		}
+		used := make(map[Cell]bool)
+		for _, path := range paths {
+			for _, c := range path {
+				used[c] = true
+			}
+		}
+		for c := range used {
+			im.heat[c.Row*cols+c.Col]++
+		}
+	}
+}
+
+func (im *boardImage) margin() int {
+	return im.cellSize / 4
+}
+
+func (im *boardImage) size() (int, int) {
+	w := 2*im.margin() + im.board.Cols()*im.cellSize
+	if im.hex {
+		w += im.cellSize / 2
+	}
+	return w, 2*im.margin() + im.board.Rows()*im.cellSize
+}
+
+// origin returns the top left corner of a cell
+func (im *boardImage) origin(row, col int) (int, int) {
+	x := im.margin() + col*im.cellSize
+	if im.hex && row%2 == 1 {
+		x += im.cellSize / 2
+	}
+	return x, im.margin() + row*im.cellSize
+}
+
+func (im *boardImage) center(c Cell) (int, int) {
+	x, y := im.origin(c.Row, c.Col)
+	return x + im.cellSize/2, y + im.cellSize/2
+}
+
+// fill returns the color of a cell's tile
+func (im *boardImage) fill(row, col int) color.RGBA {
+	if im.board.Get(row, col) == "" {
+		return blockedColor
+	}
+	if im.heat == nil {
+		return tileColor
+	}
+	most := 0
+	for _, h := range im.heat {
+		most = max(most, h)
+	}
+	if most == 0 {
+		return color.RGBA{0xff, 0xff, 0xff, 0xff}
+	}
+	return blend(color.RGBA{0xff, 0xff, 0xff, 0xff}, heatColor, float64(im.heat[row*im.board.Cols()+col])/float64(most))
+}
+
+// blend mixes a fraction t of color b into color a
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x)*(1-t) + float64(y)*t))
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xff}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// WriteSVG writes the board as an SVG image
+func (im *boardImage) WriteSVG(w io.Writer) error {
+	width, height := im.size()
+	s := im.cellSize
+	gap := max(s/16, 1)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	if im.word != "" {
+		fmt.Fprintf(&b, "<title>%s</title>\n", im.word)
+	}
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+	for r := 0; r < im.board.Rows(); r++ {
+		for c := 0; c < im.board.Cols(); c++ {
+			x, y := im.origin(r, c)
+			fmt.Fprintf(&b, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"%d\" fill=\"%s\"/>\n", x+gap, y+gap, s-2*gap, s-2*gap, s/8, hexColor(im.fill(r, c)))
+		}
+	}
+
+	if len(im.path) > 0 {
+		points := make([]string, len(im.path))
+		for i, c := range im.path {
+			x, y := im.center(c)
+			points[i] = fmt.Sprintf("%d,%d", x, y)
+		}
+		x, y := im.center(im.path[0])
+		fmt.Fprintf(&b, "<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"%s\" fill-opacity=\"0.5\"/>\n", x, y, s/3, hexColor(pathColor))
+		fmt.Fprintf(&b, "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"%d\" stroke-opacity=\"0.5\" stroke-linecap=\"round\" stroke-linejoin=\"round\"/>\n",
+			strings.Join(points, " "), hexColor(pathColor), max(s/6, 1))
+	}
+
+	for r := 0; r < im.board.Rows(); r++ {
+		for c := 0; c < im.board.Cols(); c++ {
+			face := im.board.Get(r, c)
+			if face == "" {
+				continue
+			}
+			x, y := im.center(Cell{Row: r, Col: c})
+			size := s / 2
+			if len(face) > 1 {
+				size = s * 2 / 5
+			}
+			fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" font-family=\"sans-serif\" font-size=\"%d\" font-weight=\"bold\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%s</text>\n",
+				x, y, size, hexColor(textColor), faceString(face))
+			if im.heat != nil {
+				x, y := im.origin(r, c)
+				fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" font-family=\"sans-serif\" font-size=\"%d\" fill=\"%s\">%d</text>\n",
+					x+2*gap, y+2*gap+s/6, s/6, hexColor(textColor), im.heat[r*im.board.Cols()+c])
+			}
+		}
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Image draws the board.  Letters are drawn in capitals with a built-in bitmap font.
+func (im *boardImage) Image() *image.RGBA {
+	width, height := im.size()
+	s := im.cellSize
+	gap := max(s/16, 1)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for r := 0; r < im.board.Rows(); r++ {
+		for c := 0; c < im.board.Cols(); c++ {
+			x, y := im.origin(r, c)
+			draw.Draw(img, image.Rect(x+gap, y+gap, x+s-gap, y+s-gap), image.NewUniform(im.fill(r, c)), image.Point{}, draw.Src)
+		}
+	}
+
+	if len(im.path) > 0 {
+		im.drawPath(img)
+	}
+
+	for r := 0; r < im.board.Rows(); r++ {
+		for c := 0; c < im.board.Cols(); c++ {
+			face := im.board.Get(r, c)
+			if face == "" {
+				continue
+			}
+			// Fit the letters in 60% of the cell's height and 80% of its width
+			scale := min(s*3/5/glyphHeight, s*4/5/textWidth(face))
+			x, y := im.center(Cell{Row: r, Col: c})
+			drawText(img, face, x, y, max(scale, 1), textColor)
+			if im.heat != nil {
+				count := fmt.Sprint(im.heat[r*im.board.Cols()+c])
+				small := max(s/6/glyphHeight, 1)
+				x, y := im.origin(r, c)
+				drawText(img, count, x+2*gap+textWidth(count)*small/2, y+2*gap+glyphHeight*small/2, small, textColor)
+			}
+		}
+	}
+	return img
+}
+
+// drawPath shades the pixels within reach of the path's line or its starting circle, half covering what is beneath
+func (im *boardImage) drawPath(img *image.RGBA) {
+	s := float64(im.cellSize)
+	width := math.Max(s/6, 1)
+	type point struct{ x, y float64 }
+	points := make([]point, len(im.path))
+	for i, c := range im.path {
+		x, y := im.center(c)
+		points[i] = point{float64(x), float64(y)}
+	}
+
+	near := func(px, py float64) bool {
+		if math.Hypot(px-points[0].x, py-points[0].y) <= s/3 {
+			return true
+		}
+		for i := 1; i < len(points); i++ {
+			a, b := points[i-1], points[i]
+			dx, dy := b.x-a.x, b.y-a.y
+			t := 0.
+			if l := dx*dx + dy*dy; l > 0 {
+				t = math.Max(0, math.Min(1, ((px-a.x)*dx+(py-a.y)*dy)/l))
+			}
+			if math.Hypot(px-a.x-t*dx, py-a.y-t*dy) <= width/2 {
+				return true
+			}
+		}
+		return false
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if near(float64(x)+.5, float64(y)+.5) {
+				img.SetRGBA(x, y, blend(img.RGBAAt(x, y), pathColor, .5))
+			}
+		}
+	}
+}
+
+// WritePNG writes the board as a PNG image
+func (im *boardImage) WritePNG(w io.Writer) error {
+	return png.Encode(w, im.Image())
+}
+
+// glyphs is a 5 by 7 pixel font of capital letters and digits.  Each row is five bits, leftmost pixel highest.
+var glyphs = map[rune][glyphHeight]uint8{
+	'A': {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'B': {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C': {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D': {0b11110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b11110},
+	'E': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+	'G': {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'H': {0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'I': {0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'J': {0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'K': {0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001},
+	'L': {0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111},
+	'M': {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'N': {0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001},
+	'O': {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'P': {0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000},
+	'Q': {0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101},
+	'R': {0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001},
+	'S': {0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110},
+	'T': {0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'U': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'V': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'W': {0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010},
+	'X': {0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001},
+	'Y': {0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100, 0b00100},
+	'Z': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111},
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+}
+
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// textWidth returns the width of text in font pixels, with one pixel between characters
+func textWidth(text string) int {
+	return len(text)*(glyphWidth+1) - 1
+}
+
+// drawText draws text centered on (cx, cy), with each font pixel drawn as a scale by scale square
+func drawText(img *image.RGBA, text string, cx, cy, scale int, c color.RGBA) {
+	x0 := cx - textWidth(text)*scale/2
+	y0 := cy - glyphHeight*scale/2
+	src := image.NewUniform(c)
+	for i, r := range text {
+		g := glyphs[unicode.ToUpper(r)]
+		for row := 0; row < glyphHeight; row++ {
+			for col := 0; col < glyphWidth; col++ {
+				if g[row]&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				x, y := x0+(i*(glyphWidth+1)+col)*scale, y0+row*scale
+				draw.Draw(img, image.Rect(x, y, x+scale, y+scale), src, image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	out := fs.String("o", "", "image file to write (defaults to standard output)")
+	format := fs.String("format", "", "image format (svg or png; defaults to the extension of -o, or svg)")
+	word := fs.String("word", "", "draw the path of this word")
+	heatmap := fs.Bool("heatmap", false, "shade each cell by the number of words that use it")
+	cellSize := fs.Int("cell", 60, "width of each cell in pixels")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: render [flags] board-file\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("render requires one board file")
+	}
+	if *format == "" {
+		*format = "svg"
+		if strings.EqualFold(filepath.Ext(*out), ".png") {
+			*format = "png"
+		}
+	}
+	if *format != "svg" && *format != "png" {
+		return fmt.Errorf("unknown image format %q", *format)
+	}
+	if *cellSize < 8 {
+		return errors.New("cells must be at least 8 pixels wide")
+	}
+
+	board, err := ReadBoggleBoard(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	im := newBoardImage(board, *cellSize)
+	im.hex = topo == HexTopology
+	if *word != "" || *heatmap {
+		r, err := rule()
+		if err != nil {
+			return err
+		}
+		bs, err := newSolver(board.Rows(), board.Cols(), topo, *dictfile, r)
+		if err != nil {
+			return err
+		}
+		sol := bs.findWords(board, *heatmap)
+		if *word != "" {
+			if err := im.overlayWord(sol, *word); err != nil {
+				return err
+			}
+		}
+		if *heatmap {
+			im.overlayHeatmap(sol)
+		}
+	}
+
+	write := im.WriteSVG
+	if *format == "png" {
+		write = im.WritePNG
+	}
+	if *out == "" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
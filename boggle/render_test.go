@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	var board BoggleBoard
+	if err := board.UnmarshalText([]byte("2 3\nQu I T\nE . S")); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(2, 3, GridTopology, filepath.Join("dictionaries", "dictionary-common.txt"), ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sol := bs.findWords(&board, true)
+
+	im := newBoardImage(&board, 40)
+	if err := im.overlayWord(sol, "quit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := im.overlayWord(sol, "QUITS"); err == nil {
+		t.Errorf("drew the path of a word not on the board")
+	}
+	im.overlayHeatmap(sol)
+	var words []string
+	for _, w := range sol.Words {
+		words = append(words, w.Word)
+	}
+	if !reflect.DeepEqual(words, []string{"QUIT", "SIT", "TIE"}) {
+		t.Fatalf("solution %v", words)
+	}
+	if expected := []int{1, 3, 3, 1, 0, 1}; !reflect.DeepEqual(im.heat, expected) {
+		t.Errorf("heat %v, expected %v", im.heat, expected)
+	}
+
+	var svg bytes.Buffer
+	if err := im.WriteSVG(&svg); err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(svg.Bytes(), new(struct{})); err != nil {
+		t.Errorf("invalid SVG: %v", err)
+	}
+	for _, s := range []string{`width="140" height="100"`, ">Qu</text>", `<polyline points="30,30 70,30 110,30"`, "<title>QUIT</title>"} {
+		if !strings.Contains(svg.String(), s) {
+			t.Errorf("SVG does not contain %s:\n%s", s, svg.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := im.WritePNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 140 || b.Dy() != 100 {
+		t.Errorf("image is %dx%d, expected 140x100", b.Dx(), b.Dy())
+	}
+	// Halfway between the I and the T, the path covers the white margin between tiles
+	if r, g, b, _ := img.At(90, 30).RGBA(); r>>8 != 0xe0 || g>>8 != 0xa8 || b>>8 != 0x98 {
+		t.Errorf("path color %02x%02x%02x", r>>8, g>>8, b>>8)
+	}
+	// The blocked cell is off the path
+	if c := img.At(60, 85); c != blockedColor {
+		t.Errorf("blocked cell color %v", c)
+	}
+}
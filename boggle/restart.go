@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// restartRoundSteps is the most steps each worker takes between checks for a new best board, a checkpoint, or a stop
+const restartRoundSteps = 1000
+
+// restartWorker is one hill-climbing chain of the restart method
+type restartWorker struct {
+	rng    *rand.Rand
+	src    *splitMix
+	board  *DiceBoard
+	scorer *incrementalScore
+	// kernel belongs to the worker for its whole life, so restarts allocate nothing the size of the dictionary
+	kernel *scorer
+	score  int
+}
+
+// step proposes a new board, always accepting better boards and accepting worse ones with probability
+// new score / old score.  Boards whose scores are in the cache are only rescored incrementally if accepted.
+func (w *restartWorker) step(bs *boggleSolver, freqs [][]float64, cache *scoreCache) {
+	last := w.board.Clone()
+	lastScore := w.score
+
+	changed := w.board.DictShuffle(w.rng, bs.adjList, freqs)
+	key, score, known := cache.lookup(w.board)
+	if !known {
+		score = w.scorer.update(w.board, changed)
+		cache.store(key, score)
+	}
+	w.score = score
+
+	if w.score <= lastScore && w.rng.Float64() > float64(w.score)/float64(lastScore) {
+		w.board = last.(*DiceBoard)
+		if !known {
+			w.scorer.revert()
+		}
+		w.score = lastScore
+	} else if known {
+		// The score was remembered, but the incremental scorer must still follow the board
+		w.scorer.update(w.board, changed)
+	}
+}
+
+// reset starts the worker from a fresh board drawn from its own source
+func (w *restartWorker) reset(bs *boggleSolver, opts optimizeOptions) {
+	w.board = newDiceBoard(w.rng, opts.rows, opts.cols, opts.dice)
+	w.scorer = bs.newIncremental(w.board, w.kernel)
+	w.score = w.scorer.Score()
+}
+
+// restarting runs independent hill-climbing workers, restarting one of them, in turn, from a fresh board
+// every interval steps.  Workers draw only from their own sources and take the same number of steps each
+// round, and ties for the best board go to the lowest-numbered worker, so a run is reproducible from its seed.
+type restarting struct {
+	solver *boggleSolver
+	freqs  [][]float64
+	opts   optimizeOptions
+	rng    *rand.Rand
+	src    *splitMix
+	// cache remembers the scores of boards the workers have visited
+	cache *scoreCache
+
+	workers []*restartWorker
+	// interval is the number of steps between restarts, and steps the number taken since the last restart
+	interval int
+	steps    int
+	// restarts counts the restarts so far; the next restart is of worker restarts % len(workers)
+	restarts int
+
+	best      int
+	bestBoard []string
+}
+
+// newRestarting starts each worker from a random board, with its own source seeded from the given seed
+func newRestarting(seed int64, bs *boggleSolver, freqs [][]float64, opts optimizeOptions, workers int, interval int) *restarting {
+	r := &restarting{
+		solver:   bs,
+		freqs:    freqs,
+		opts:     opts,
+		cache:    newScoreCache(opts.rows, opts.cols, bs.adjList, opts.cacheSize),
+		workers:  make([]*restartWorker, workers),
+		interval: interval,
+	}
+	r.rng, r.src = newSplitMixRand(seed)
+	for k := range r.workers {
+		w := &restartWorker{kernel: newScorer(bs)}
+		w.rng, w.src = newSplitMixRand(r.rng.Int63())
+		w.reset(bs, opts)
+		r.workers[k] = w
+		r.record(w)
+	}
+	return r
+}
+
+// restoreRestarting resumes a restart run saved with checkpoint
+func restoreRestarting(cp *checkpoint, bs *boggleSolver, freqs [][]float64, opts optimizeOptions, workers int, interval int) (*restarting, error) {
+	if len(cp.Workers) != workers || len(cp.Swaps) != 0 {
+		return nil, fmt.Errorf("checkpoint is not from a restart run with %d workers", workers)
+	}
+	if cp.Steps < 0 || cp.Steps >= interval {
+		return nil, errors.New("checkpoint steps since the last restart must be below the restart interval")
+	}
+	r := &restarting{
+		solver:    bs,
+		freqs:     freqs,
+		opts:      opts,
+		src:       &splitMix{state: cp.RNG},
+		cache:     newScoreCache(opts.rows, opts.cols, bs.adjList, opts.cacheSize),
+		workers:   make([]*restartWorker, workers),
+		interval:  interval,
+		steps:     cp.Steps,
+		restarts:  cp.Round,
+		best:      cp.Best,
+		bestBoard: cp.BestBoard,
+	}
+	r.rng = rand.New(r.src)
+	for k, ws := range cp.Workers {
+		board, err := ws.Board.restore(opts.rows, opts.cols, opts.dice)
+		if err != nil {
+			return nil, err
+		}
+		src := &splitMix{state: ws.RNG}
+		w := &restartWorker{rng: rand.New(src), src: src, board: board, kernel: newScorer(bs), score: ws.Score}
+		w.scorer = bs.newIncremental(board, w.kernel)
+		r.workers[k] = w
+	}
+	return r, nil
+}
+
+// checkpoint saves the state of every worker between rounds
+func (r *restarting) checkpoint() *checkpoint {
+	cp := &checkpoint{
+		Round:     r.restarts,
+		Steps:     r.steps,
+		RNG:       r.src.state,
+		Best:      r.best,
+		BestBoard: r.bestBoard,
+		Workers:   make([]workerState, len(r.workers)),
+	}
+	for k, w := range r.workers {
+		cp.Workers[k] = workerState{Board: w.board.state(), Score: w.score, RNG: w.src.state}
+	}
+	return cp
+}
+
+// round runs every worker concurrently for the same number of steps, stopping early at the next restart,
+// and then restarts a worker if one is due.  It returns true if a new best board was found.
+func (r *restarting) round() bool {
+	steps := min(restartRoundSteps, r.interval-r.steps)
+	var wg sync.WaitGroup
+	bests := make([]boardScore, len(r.workers))
+	for k, w := range r.workers {
+		wg.Add(1)
+		go func(k int, w *restartWorker) {
+			defer wg.Done()
+			for s := 0; s < steps; s++ {
+				w.step(r.solver, r.freqs, r.cache)
+				if w.score > bests[k].score {
+					bests[k] = boardScore{score: w.score, board: w.board.ArrayLinear()}
+				}
+			}
+		}(k, w)
+	}
+	wg.Wait()
+
+	// Workers are checked in order, so the lowest-numbered worker wins a tie
+	improved := false
+	for _, b := range bests {
+		if b.score > r.best {
+			r.best = b.score
+			r.bestBoard = b.board
+			improved = true
+		}
+	}
+
+	r.steps += steps
+	if r.steps == r.interval {
+		r.workers[r.restarts%len(r.workers)].reset(r.solver, r.opts)
+		r.restarts++
+		r.steps = 0
+	}
+	return improved
+}
+
+func (r *restarting) record(w *restartWorker) {
+	if w.score > r.best {
+		r.best = w.score
+		r.bestBoard = w.board.ArrayLinear()
+	}
+}
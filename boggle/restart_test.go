@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRestarting(t *testing.T) {
+	opts, bs, freqs := testOptimizer(t)
+	opts.cacheSize = 1 << 16
+
+	// Restarts fall every 1500 steps, partway through a round
+	uninterrupted := newRestarting(99, bs, freqs, opts, 3, 1500)
+	interrupted := newRestarting(99, bs, freqs, opts, 3, 1500)
+	for i := 0; i < 3; i++ {
+		uninterrupted.round()
+		interrupted.round()
+	}
+	if uninterrupted.restarts != 1 || uninterrupted.steps != 1000 {
+		t.Errorf("%d restarts and %d steps since, expected 1 and 1000", uninterrupted.restarts, uninterrupted.steps)
+	}
+	for i, w := range uninterrupted.workers {
+		if s := bs.score(w.board); s != w.score || w.scorer.Score() != s {
+			t.Errorf("worker %d scores %d, recorded as %d with %d from its scorer", i, s, w.score, w.scorer.Score())
+		}
+	}
+
+	resumed, err := restoreRestarting(interrupted.checkpoint(), bs, freqs, opts, 3, 1500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		uninterrupted.round()
+		resumed.round()
+	}
+	for i, w := range uninterrupted.workers {
+		if got, expected := resumed.workers[i].board.ArrayLinear(), w.board.ArrayLinear(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("resumed worker %d ended on %v, expected %v", i, got, expected)
+		}
+	}
+	if resumed.best != uninterrupted.best || !reflect.DeepEqual(resumed.bestBoard, uninterrupted.bestBoard) || resumed.restarts != uninterrupted.restarts {
+		t.Errorf("resumed run found %d after %d restarts, expected %d after %d", resumed.best, resumed.restarts, uninterrupted.best, uninterrupted.restarts)
+	}
+	if bs.score(mustBoard(t, uninterrupted.bestBoard)) != uninterrupted.best {
+		t.Errorf("best board %v does not score %d", uninterrupted.bestBoard, uninterrupted.best)
+	}
+
+	if _, err := restoreRestarting(interrupted.checkpoint(), bs, freqs, opts, 4, 1500); err == nil {
+		t.Errorf("restored a checkpoint of 3 workers as 4")
+	}
+}
+
+// mustBoard makes a 4-by-4 board from its cells, as reported by ArrayLinear
+func mustBoard(t *testing.T, cells []string) *BoggleBoard {
+	t.Helper()
+	board := make([][]string, 4)
+	for i := range board {
+		board[i] = cells[4*i : 4*i+4]
+	}
+	bb, err := NewBoggleBoardArray(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bb
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ScoringRule decides how many points a word found on the board is worth
+type ScoringRule interface {
+	// MinLength returns the length of the shortest word that can score
+	MinLength() int
+	// Score returns the points awarded for the word, or zero if the word does not count
+	Score(word string) int
+}
+
+// lengthRule scores words by length alone
+type lengthRule struct {
+	minLength int
+	// points[i] is the score of a word of length minLength+i; longer words score the last entry
+	points []int
+}
+
+// MinLength implements ScoringRule's interface
+func (lr *lengthRule) MinLength() int {
+	return lr.minLength
+}
+
+// Score implements ScoringRule's interface
+func (lr *lengthRule) Score(word string) int {
+	i := len(word) - lr.minLength
+	if i < 0 || len(lr.points) == 0 {
+		return 0
+	}
+	if i >= len(lr.points) {
+		i = len(lr.points) - 1
+	}
+	return lr.points[i]
+}
+
+// ClassicRule is the standard 4-by-4 Boggle scoring: words of 3, 4, 5, 6, 7, or 8+ letters score 1, 1, 2, 3, 5, and 11 points
+var ClassicRule ScoringRule = &lengthRule{minLength: 3, points: []int{1, 1, 2, 3, 5, 11}}
+
+// BigBoggleRule is the Big Boggle scoring: words of 4, 5, 6, 7, or 8+ letters score 1, 2, 3, 5, and 11 points
+var BigBoggleRule ScoringRule = &lengthRule{minLength: 4, points: []int{1, 2, 3, 5, 11}}
+
+// MasterRule is the Boggle Master/Boggle Deluxe scoring, which uses the Big Boggle table
+var MasterRule = BigBoggleRule
+
+// ReadScoringTable reads a per-length scoring table from a file.
+// Each non-blank line holds a word length and the points awarded to words of that length.
+// Lengths not listed score the same as the next shorter listed length, and lengths shorter than
+// the shortest listed length do not score.  Lines beginning with '#' are ignored.
+func ReadScoringTable(filename string) (ScoringRule, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	table := make(map[int]int)
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected length and points, got %q", filename, line, text)
+		}
+		length, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", filename, line, err)
+		}
+		points, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", filename, line, err)
+		}
+		if length < 1 || points < 0 {
+			return nil, fmt.Errorf("%s:%d: invalid length %d or points %d", filename, line, length, points)
+		}
+		table[length] = points
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(table) == 0 {
+		return nil, fmt.Errorf("%s: empty scoring table", filename)
+	}
+
+	lengths := make([]int, 0, len(table))
+	for l := range table {
+		lengths = append(lengths, l)
+	}
+	sort.Ints(lengths)
+
+	minLength := lengths[0]
+	points := make([]int, lengths[len(lengths)-1]-minLength+1)
+	for i := range points {
+		if p, ok := table[minLength+i]; ok {
+			points[i] = p
+		} else {
+			points[i] = points[i-1]
+		}
+	}
+	return &lengthRule{minLength: minLength, points: points}, nil
+}
+
+// letterValues are the Scrabble tile values of the letters A through Z
+var letterValues = [26]int{
+	1, 3, 3, 2, 1, 4, 2, 4, 1, 8, 5, 1, 3,
+	1, 1, 3, 10, 1, 1, 1, 1, 4, 4, 8, 4, 10,
+}
+
+// letterRule scores words by the sum of the Scrabble values of their letters
+type letterRule struct {
+	minLength int
+}
+
+// LetterValueRule scores words of 3 or more letters by summing the Scrabble values of their letters
+var LetterValueRule ScoringRule = &letterRule{minLength: 3}
+
+// MinLength implements ScoringRule's interface
+func (lr *letterRule) MinLength() int {
+	return lr.minLength
+}
+
+// Score implements ScoringRule's interface
+func (lr *letterRule) Score(word string) int {
+	if len(word) < lr.minLength {
+		return 0
+	}
+	score := 0
+	for _, r := range word {
+		if r < 'A' || r > 'Z' {
+			return 0
+		}
+		score += letterValues[r-'A']
+	}
+	return score
+}
+
+// NewScoringRule looks up a scoring rule by name.
+// The known names are "classic", "big", "master", "letters", and "table", the last of which reads
+// a per-length table from tablefile using ReadScoringTable.
+func NewScoringRule(name string, tablefile string) (ScoringRule, error) {
+	switch strings.ToLower(name) {
+	case "classic":
+		return ClassicRule, nil
+	case "big":
+		return BigBoggleRule, nil
+	case "master":
+		return MasterRule, nil
+	case "letters":
+		return LetterValueRule, nil
+	case "table":
+		if tablefile == "" {
+			return nil, fmt.Errorf("scoring rule %q requires a table file", name)
+		}
+		return ReadScoringTable(tablefile)
+	default:
+		return nil, fmt.Errorf("unknown scoring rule %q", name)
+	}
+}
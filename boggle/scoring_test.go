@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestScoringRules(t *testing.T) {
+	tests := []struct {
+		rule  ScoringRule
+		word  string
+		score int
+	}{
+		{ClassicRule, "AT", 0},
+		{ClassicRule, "CAT", 1},
+		{ClassicRule, "CATS", 1},
+		{ClassicRule, "CRATES", 3},
+		{ClassicRule, "DISTRACTED", 11},
+		{BigBoggleRule, "CAT", 0},
+		{BigBoggleRule, "CATS", 1},
+		{BigBoggleRule, "CRATES", 3},
+		{LetterValueRule, "AT", 0},
+		{LetterValueRule, "QUIZ", 22},
+	}
+	for _, tt := range tests {
+		if s := tt.rule.Score(tt.word); s != tt.score {
+			t.Errorf("score of %s = %d, expected %d", tt.word, s, tt.score)
+		}
+	}
+}
+
+func TestReadScoringTable(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "table.txt")
+	table := "# house rules\n4 2\n6 5\n\n9 20\n"
+	if err := ioutil.WriteFile(fn, []byte(table), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := NewScoringRule("table", fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.MinLength() != 4 {
+		t.Errorf("minimum length %d != expected 4", rule.MinLength())
+	}
+
+	expected := map[string]int{
+		"CAT":          0,
+		"CATS":         2,
+		"CRATE":        2,
+		"CRATES":       5,
+		"CRATERS":      5,
+		"CRATERING":    20,
+		"CRATERINGSSS": 20,
+	}
+	for w, e := range expected {
+		if s := rule.Score(w); s != e {
+			t.Errorf("score of %s = %d, expected %d", w, s, e)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxServerCells limits the size of the boards the server solves
+const maxServerCells = 256
+
+// maxBoardBytes limits the size of a posted board
+const maxBoardBytes = 64 << 10
+
+// maxCachedSolvers limits the number of solvers, one for each dictionary and board size, kept between requests
+const maxCachedSolvers = 16
+
+// server answers HTTP requests to solve, roll, and check boards, keeping its dictionaries loaded between requests
+type server struct {
+	// dictionaries maps each dictionary's name to its file; requests that name no dictionary use defaultDict
+	dictionaries map[string]string
+	defaultDict  string
+	rule         ScoringRule
+	topology     Topology
+	// origin, if not empty, is allowed to call the server from a browser
+	origin string
+
+	// solversMu guards solvers, and mu guards rng
+	solversMu sync.Mutex
+	solvers   map[solverKey]*boggleSolver
+	mu        sync.Mutex
+	rng       *rand.Rand
+}
+
+type solverKey struct {
+	dict       string
+	rows, cols int
+}
+
+// newServer loads each dictionary file, naming it after the file without its extension.  The first is the default.
+func newServer(dictfiles []string, rule ScoringRule, topology Topology, seed int64) (*server, error) {
+	if len(dictfiles) == 0 {
+		return nil, errors.New("no dictionaries to serve")
+	}
+	s := &server{
+		dictionaries: make(map[string]string),
+		rule:         rule,
+		topology:     topology,
+		solvers:      make(map[solverKey]*boggleSolver),
+	}
+	s.rng, _ = newSplitMixRand(seed)
+	for _, fn := range dictfiles {
+		name := strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+		if _, ok := s.dictionaries[name]; ok {
+			return nil, fmt.Errorf("two dictionaries named %s", name)
+		}
+		if _, err := loadDictionary(fn); err != nil {
+			return nil, err
+		}
+		s.dictionaries[name] = fn
+		if s.defaultDict == "" {
+			s.defaultDict = name
+		}
+	}
+	return s, nil
+}
+
+// Handler returns the server's endpoints
+func (s *server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solve", s.handle(http.MethodPost, s.solve))
+	mux.HandleFunc("/check", s.handle(http.MethodPost, s.check))
+	mux.HandleFunc("/roll", s.handle(http.MethodGet, s.roll))
+	mux.HandleFunc("/dictionaries", s.handle(http.MethodGet, s.listDictionaries))
+	return mux
+}
+
+// httpError is an error reported to the client with an HTTP status
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// handle adapts an endpoint that returns a value to encode as JSON or an error to report
+func (s *server) handle(method string, endpoint func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", s.origin)
+			w.Header().Set("Access-Control-Allow-Methods", method)
+		}
+		if r.Method == http.MethodOptions && s.origin != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var v interface{}
+		var err error
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			err = &httpError{http.StatusMethodNotAllowed, fmt.Sprintf("%s requires %s", r.URL.Path, method)}
+		} else {
+			v, err = endpoint(r)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			status := http.StatusInternalServerError
+			if he, ok := err.(*httpError); ok {
+				status = he.status
+			} else {
+				log.Printf("%s %s: %v", r.Method, r.URL, err)
+			}
+			w.WriteHeader(status)
+			v = struct {
+				Error string `json:"error"`
+			}{err.Error()}
+		}
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			log.Printf("%s %s: %v", r.Method, r.URL, err)
+		}
+	}
+}
+
+// solver returns the solver for a board's size and the dictionary named in the request, creating it on first use.
+// At most maxCachedSolvers solvers are kept.
+func (s *server) solver(r *http.Request, rows, cols int) (*boggleSolver, error) {
+	name := r.URL.Query().Get("dict")
+	if name == "" {
+		name = s.defaultDict
+	}
+	dictfile, ok := s.dictionaries[name]
+	if !ok {
+		return nil, &httpError{http.StatusNotFound, fmt.Sprintf("no dictionary named %q", name)}
+	}
+
+	key := solverKey{name, rows, cols}
+	s.solversMu.Lock()
+	bs, ok := s.solvers[key]
+	s.solversMu.Unlock()
+	if ok {
+		return bs, nil
+	}
+
+	// Building a solver scores every word of the dictionary, so it is done without holding the lock.
+	// If two requests build the same solver at once, the first one stored is kept.
+	bs, err := newSolver(rows, cols, s.topology, dictfile, s.rule)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	s.solversMu.Lock()
+	defer s.solversMu.Unlock()
+	if cached, ok := s.solvers[key]; ok {
+		return cached, nil
+	}
+	if len(s.solvers) >= maxCachedSolvers {
+		// Make room by dropping any one solver; it is rebuilt if it is needed again
+		for k := range s.solvers {
+			delete(s.solvers, k)
+			break
+		}
+	}
+	s.solvers[key] = bs
+	return bs, nil
+}
+
+// readBoard reads a board posted in the format of board files
+func readBoard(r *http.Request) (*BoggleBoard, error) {
+	text, err := io.ReadAll(io.LimitReader(r.Body, maxBoardBytes+1))
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	if len(text) > maxBoardBytes {
+		return nil, &httpError{http.StatusRequestEntityTooLarge, fmt.Sprintf("boards are limited to %d bytes", maxBoardBytes)}
+	}
+	var board BoggleBoard
+	if err := board.UnmarshalText(text); err != nil {
+		return nil, badRequest("%v", err)
+	}
+	if board.Rows()*board.Cols() > maxServerCells {
+		return nil, badRequest("boards are limited to %d cells", maxServerCells)
+	}
+	return &board, nil
+}
+
+// solve returns the Solution of the posted board.  With paths=true, every path spelling each word is included.
+func (s *server) solve(r *http.Request) (interface{}, error) {
+	board, err := readBoard(r)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := s.solver(r, board.Rows(), board.Cols())
+	if err != nil {
+		return nil, err
+	}
+	allPaths, _ := strconv.ParseBool(r.URL.Query().Get("paths"))
+	return bs.findWords(board, allPaths), nil
+}
+
+// checkResult is the answer to a word checked against a board
+type checkResult struct {
+	Word   string `json:"word"`
+	Valid  bool   `json:"valid"`
+	Score  int    `json:"score"`
+	Path   []Cell `json:"path,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// check checks the word given in the request against the posted board
+func (s *server) check(r *http.Request) (interface{}, error) {
+	word := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("word")))
+	if word == "" {
+		return nil, badRequest("no word given")
+	}
+	board, err := readBoard(r)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := s.solver(r, board.Rows(), board.Cols())
+	if err != nil {
+		return nil, err
+	}
+	w, err := bs.checkWord(board, word)
+	if err != nil {
+		return checkResult{Word: word, Reason: rejectReason(err)}, nil
+	}
+	return checkResult{Word: word, Valid: true, Score: w.Score, Path: w.Path}, nil
+}
+
+// rolledBoard is a board rolled by the server, both as cells and in the format of board files
+type rolledBoard struct {
+	Rows  int      `json:"rows"`
+	Cols  int      `json:"cols"`
+	Board []string `json:"board"`
+	Text  string   `json:"text"`
+}
+
+// roll rolls a board from a built-in dice set.  Dice files are not read, so clients cannot name files on the server.
+func (s *server) roll(r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	diceName := q.Get("dice")
+	if diceName == "" {
+		diceName = "1992"
+	}
+	switch strings.ToLower(diceName) {
+	case "1992", "1983", "master", "big", "random":
+	default:
+		return nil, &httpError{http.StatusNotFound, fmt.Sprintf("no dice set named %q", diceName)}
+	}
+	rows, cols := 4, 4
+	for _, p := range []struct {
+		name string
+		v    *int
+	}{{"rows", &rows}, {"cols", &cols}} {
+		if text := q.Get(p.name); text != "" {
+			n, err := strconv.Atoi(text)
+			if err != nil || n < 1 {
+				return nil, badRequest("%s must be a positive integer", p.name)
+			}
+			*p.v = n
+		}
+	}
+	if rows*cols > maxServerCells {
+		return nil, badRequest("boards are limited to %d cells", maxServerCells)
+	}
+	ro, err := newRoller(diceName, rows, cols)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+
+	var board *BoggleBoard
+	if text := q.Get("seed"); text != "" {
+		seed, err := strconv.ParseInt(text, 10, 64)
+		if err != nil || seed == 0 {
+			return nil, badRequest("seed must be a nonzero integer")
+		}
+		rng, _ := newSplitMixRand(seed)
+		board = ro.roll(rng)
+	} else {
+		s.mu.Lock()
+		board = ro.roll(s.rng)
+		s.mu.Unlock()
+	}
+	return rolledBoard{Rows: board.Rows(), Cols: board.Cols(), Board: board.ArrayLinear(), Text: board.String()}, nil
+}
+
+// listDictionaries returns the names of the dictionaries served, the default first
+func (s *server) listDictionaries(r *http.Request) (interface{}, error) {
+	names := []string{s.defaultDict}
+	for name := range s.dictionaries {
+		if name != s.defaultDict {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names, nil
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	dicts := fs.String("dict", defaultDictionary, "comma-separated dictionary files to serve, the first being the default")
+	origin := fs.String("origin", "", "origin allowed to call the server from a browser, such as http://localhost:3000 (* allows any)")
+	seed := fs.Int64("seed", 0, "random seed for rolled boards (0 uses the clock)")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Parse(args)
+
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	s, err := newServer(strings.Split(*dicts, ","), r, topo, *seed)
+	if err != nil {
+		return err
+	}
+	s.origin = *origin
+
+	log.Printf("serving %d dictionaries on http://%s", len(s.dictionaries), *addr)
+	srv := &http.Server{Addr: *addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	common := filepath.Join("dictionaries", "dictionary-common.txt")
+	nursery := filepath.Join("dictionaries", "dictionary-nursery.txt")
+	s, err := newServer([]string{common, nursery}, ClassicRule, GridTopology, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	// do makes a request and decodes the JSON response into v, returning the status
+	do := func(method, path, body string, v interface{}) int {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		return resp.StatusCode
+	}
+	const board = "2 3\nQu I T\nE . S"
+
+	var sol Solution
+	if status := do("POST", "/solve", board, &sol); status != http.StatusOK {
+		t.Fatalf("solve status %d", status)
+	}
+	var words []string
+	for _, w := range sol.Words {
+		words = append(words, w.Word)
+	}
+	if !reflect.DeepEqual(words, []string{"QUIT", "SIT", "TIE"}) || sol.Score != 3 {
+		t.Errorf("solution %v scoring %d", words, sol.Score)
+	}
+
+	var check checkResult
+	do("POST", "/check?word=quit", board, &check)
+	expected := checkResult{Word: "QUIT", Valid: true, Score: 1, Path: []Cell{{0, 0}, {0, 1}, {0, 2}}}
+	if !reflect.DeepEqual(check, expected) {
+		t.Errorf("check %+v, expected %+v", check, expected)
+	}
+	check = checkResult{}
+	do("POST", "/check?word=TEQU", board, &check)
+	if check.Valid || check.Reason != "not on the board" {
+		t.Errorf("check %+v", check)
+	}
+
+	var rolled rolledBoard
+	if status := do("GET", "/roll?dice=master&seed=42", "", &rolled); status != http.StatusOK {
+		t.Fatalf("roll status %d", status)
+	}
+	if rolled.Rows != 5 || rolled.Cols != 5 || len(rolled.Board) != 25 {
+		t.Errorf("rolled %+v", rolled)
+	}
+	// A rolled board can be posted back to be solved, and the same seed rolls the same board
+	var again rolledBoard
+	do("GET", "/roll?dice=master&seed=42", "", &again)
+	if !reflect.DeepEqual(rolled, again) {
+		t.Errorf("rolled %+v, then %+v with the same seed", rolled, again)
+	}
+	if status := do("POST", "/solve?dict=dictionary-nursery", rolled.Text, &sol); status != http.StatusOK || sol.Rows != 5 {
+		t.Errorf("solve of rolled board status %d", status)
+	}
+
+	// Solving boards of many sizes keeps only a limited number of solvers
+	for n := 1; n <= maxCachedSolvers+4; n++ {
+		text := fmt.Sprintf("1 %d\n%s", n, strings.Repeat("A ", n))
+		if status := do("POST", "/solve", text, &sol); status != http.StatusOK {
+			t.Fatalf("solve of 1x%d board status %d", n, status)
+		}
+	}
+	if len(s.solvers) > maxCachedSolvers {
+		t.Errorf("%d solvers kept, expected at most %d", len(s.solvers), maxCachedSolvers)
+	}
+
+	var names []string
+	do("GET", "/dictionaries", "", &names)
+	if !reflect.DeepEqual(names, []string{"dictionary-common", "dictionary-nursery"}) {
+		t.Errorf("dictionaries %v", names)
+	}
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{"GET", "/solve", "", http.StatusMethodNotAllowed},
+		{"POST", "/solve", "2 2\nA B", http.StatusBadRequest},
+		{"POST", "/solve?dict=nonesuch", board, http.StatusNotFound},
+		{"POST", "/solve", "20 20\n" + strings.Repeat("A ", 400), http.StatusBadRequest},
+		{"POST", "/check", board, http.StatusBadRequest},
+		{"GET", "/roll?dice=/etc/passwd", "", http.StatusNotFound},
+		{"GET", "/roll?dice=random&rows=0", "", http.StatusBadRequest},
+	} {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if status := do(tc.method, tc.path, tc.body, &e); status != tc.status || e.Error == "" {
+			t.Errorf("%s %s: status %d, error %q; expected status %d", tc.method, tc.path, status, e.Error, tc.status)
+		}
+	}
+}
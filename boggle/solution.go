@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Cell is a position on a board
+type Cell struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+func (c Cell) String() string {
+	return fmt.Sprintf("(%d,%d)", c.Row, c.Col)
+}
+
+// WordResult is a word found on a board along with the cells that spell it
+type WordResult struct {
+	Word  string `json:"word"`
+	Score int    `json:"score"`
+	// Path is the first path found that spells the word
+	Path []Cell `json:"path"`
+	// Paths lists every distinct path that spells the word, if requested
+	Paths [][]Cell `json:"paths,omitempty"`
+}
+
+// Solution lists every word that can be found on a board
+type Solution struct {
+	Rows  int          `json:"rows"`
+	Cols  int          `json:"cols"`
+	Board []string     `json:"board"`
+	Score int          `json:"score"`
+	Words []WordResult `json:"words"`
+}
+
+// findWords solves the board, recording the path used to spell each word.
+// If allPaths is true, every distinct path spelling each word is recorded as well.
+// Words are sorted alphabetically.
+func (bs *boggleSolver) findWords(bb Boggler, allPaths bool) *Solution {
+	f := wordFinder{
+		solver:   bs,
+		board:    bb,
+		allPaths: allPaths,
+		visited:  make([]bool, len(bs.adjList)),
+		index:    make(map[string]int),
+	}
+	for p := range bs.adjList {
+		f.dfs(bs.dictionary.Root(), p)
+	}
+
+	sort.Slice(f.words, func(i, j int) bool { return f.words[i].Word < f.words[j].Word })
+	sol := &Solution{Rows: bb.Rows(), Cols: bb.Cols(), Board: bb.ArrayLinear(), Words: f.words}
+	for _, w := range f.words {
+		sol.Score += w.Score
+	}
+	return sol
+}
+
+type wordFinder struct {
+	solver   *boggleSolver
+	board    Boggler
+	allPaths bool
+	visited  []bool
+	path     []int
+	buf      bytes.Buffer
+	index    map[string]int
+	words    []WordResult
+}
+
+func (f *wordFinder) dfs(dictionary DAWGNode, p int) {
+	if f.visited[p] {
+		return
+	}
+
+	letter := f.board.GetLinear(p)
+	if letter == "" {
+		return
+	}
+	subtrie, ok := dictionary.Subtrie(letter)
+	if !ok {
+		return
+	}
+
+	f.visited[p] = true
+	f.path = append(f.path, p)
+	n := f.buf.Len()
+	f.buf.WriteString(letter)
+
+	if score := subtrie.RootValue(); score > 0 {
+		f.record(f.buf.String(), score)
+	}
+
+	for _, p2 := range f.solver.adjList[p] {
+		f.dfs(subtrie, p2)
+	}
+
+	f.visited[p] = false
+	f.path = f.path[:len(f.path)-1]
+	f.buf.Truncate(n)
+}
+
+func (f *wordFinder) record(word string, score int) {
+	i, found := f.index[word]
+	if found && !f.allPaths {
+		return
+	}
+	path := f.cells()
+	if !found {
+		f.index[word] = len(f.words)
+		f.words = append(f.words, WordResult{Word: word, Score: score, Path: path})
+		i = len(f.words) - 1
+	}
+	if f.allPaths {
+		f.words[i].Paths = append(f.words[i].Paths, path)
+	}
+}
+
+func (f *wordFinder) cells() []Cell {
+	cols := f.solver.cols
+	path := make([]Cell, len(f.path))
+	for i, p := range f.path {
+		path[i] = Cell{Row: p / cols, Col: p % cols}
+	}
+	return path
+}
+
+// formatPath writes a path as a space-separated list of cells
+func formatPath(path []Cell) string {
+	s := make([]string, len(path))
+	for i, c := range path {
+		s[i] = c.String()
+	}
+	return strings.Join(s, " ")
+}
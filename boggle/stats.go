@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// boardStats describes the boards rolled from a set of dice
+type boardStats struct {
+	Boards int `json:"boards"`
+	// Score, Words, and Longest summarize each board's total score, number of distinct words, and longest word in letters
+	Score   summary `json:"score"`
+	Words   summary `json:"words"`
+	Longest summary `json:"longest"`
+	// LongestWord is the longest word found on any board, preferring the first in alphabetical order
+	LongestWord string `json:"longest_word"`
+	// WordFrequency lists words by the fraction of boards they appear on, most frequent first
+	WordFrequency []wordFrequency `json:"word_frequency"`
+}
+
+// summary describes the distribution of a value over many boards
+type summary struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Min    int     `json:"min"`
+	P10    int     `json:"p10"`
+	P25    int     `json:"p25"`
+	Median int     `json:"median"`
+	P75    int     `json:"p75"`
+	P90    int     `json:"p90"`
+	Max    int     `json:"max"`
+}
+
+type wordFrequency struct {
+	Word        string  `json:"word"`
+	Boards      int     `json:"boards"`
+	Probability float64 `json:"probability"`
+}
+
+// summarize computes the mean, standard deviation, and percentiles of a list of values
+func summarize(values []int) summary {
+	if len(values) == 0 {
+		return summary{}
+	}
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+
+	sum := 0.
+	for _, v := range sorted {
+		sum += float64(v)
+	}
+	mean := sum / float64(len(sorted))
+	variance := 0.
+	for _, v := range sorted {
+		variance += (float64(v) - mean) * (float64(v) - mean)
+	}
+
+	// Percentiles are taken by the nearest-rank method
+	percentile := func(p int) int {
+		rank := (p*len(sorted) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+	return summary{
+		Mean:   mean,
+		StdDev: math.Sqrt(variance / float64(len(sorted))),
+		Min:    sorted[0],
+		P10:    percentile(10),
+		P25:    percentile(25),
+		Median: percentile(50),
+		P75:    percentile(75),
+		P90:    percentile(90),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// collectStats rolls n boards and solves them with the given number of workers.
+// Boards are rolled in order from rng, so the statistics depend only on its seed.
+func collectStats(bs *boggleSolver, roll func(*rand.Rand) *BoggleBoard, rng *rand.Rand, n int, workers int) *boardStats {
+	boards := make(chan *BoggleBoard, workers)
+	go func() {
+		for i := 0; i < n; i++ {
+			boards <- roll(rng)
+		}
+		close(boards)
+	}()
+
+	var mu sync.Mutex
+	scores := make([]int, 0, n)
+	words := make([]int, 0, n)
+	longest := make([]int, 0, n)
+	counts := make(map[string]int)
+	longestWord := ""
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for board := range boards {
+				sol := bs.findWords(board, false)
+				long := ""
+				for _, w := range sol.Words {
+					if len(w.Word) > len(long) {
+						long = w.Word
+					}
+				}
+
+				mu.Lock()
+				scores = append(scores, sol.Score)
+				words = append(words, len(sol.Words))
+				longest = append(longest, len(long))
+				for _, w := range sol.Words {
+					counts[w.Word]++
+				}
+				if len(long) > len(longestWord) || (len(long) == len(longestWord) && long < longestWord) {
+					longestWord = long
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := &boardStats{
+		Boards:      n,
+		Score:       summarize(scores),
+		Words:       summarize(words),
+		Longest:     summarize(longest),
+		LongestWord: longestWord,
+	}
+	for w, c := range counts {
+		stats.WordFrequency = append(stats.WordFrequency, wordFrequency{Word: w, Boards: c, Probability: float64(c) / float64(n)})
+	}
+	sort.Slice(stats.WordFrequency, func(i, j int) bool {
+		a, b := stats.WordFrequency[i], stats.WordFrequency[j]
+		if a.Boards != b.Boards {
+			return a.Boards > b.Boards
+		}
+		return a.Word < b.Word
+	})
+	return stats
+}
+
+// writeCSV writes the statistics as two tables separated by a blank line: the summary of each
+// per-board value, then the probability of each word
+func (s *boardStats) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"statistic", "boards", "mean", "stddev", "min", "p10", "p25", "median", "p75", "p90", "max"})
+	for _, row := range []struct {
+		name string
+		sum  summary
+	}{{"score", s.Score}, {"words", s.Words}, {"longest", s.Longest}} {
+		cw.Write([]string{
+			row.name,
+			strconv.Itoa(s.Boards),
+			strconv.FormatFloat(row.sum.Mean, 'f', 3, 64),
+			strconv.FormatFloat(row.sum.StdDev, 'f', 3, 64),
+			strconv.Itoa(row.sum.Min),
+			strconv.Itoa(row.sum.P10),
+			strconv.Itoa(row.sum.P25),
+			strconv.Itoa(row.sum.Median),
+			strconv.Itoa(row.sum.P75),
+			strconv.Itoa(row.sum.P90),
+			strconv.Itoa(row.sum.Max),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	cw.Write([]string{"word", "boards", "probability"})
+	for _, f := range s.WordFrequency {
+		cw.Write([]string{f.Word, strconv.Itoa(f.Boards), strconv.FormatFloat(f.Probability, 'f', 6, 64)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	dice := rollFlags(fs)
+	n := fs.Int("n", 1000, "number of boards to roll")
+	dictfile := fs.String("dict", defaultDictionary, "dictionary file")
+	seed := fs.Int64("seed", 0, "random seed (0 uses the clock)")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "number of boards to solve concurrently")
+	format := fs.String("format", "csv", "output format (csv or json)")
+	top := fs.Int("top", 100, "number of most frequent words to report (0 reports every word found)")
+	rule := scoringFlags(fs)
+	topology := topologyFlag(fs)
+	fs.Parse(args)
+
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown output format %q", *format)
+	}
+	if *n < 1 || *workers < 1 {
+		return fmt.Errorf("need at least one board and one worker")
+	}
+	ro, err := dice()
+	if err != nil {
+		return err
+	}
+	r, err := rule()
+	if err != nil {
+		return err
+	}
+	topo, err := topology()
+	if err != nil {
+		return err
+	}
+	bs, err := newSolver(ro.rows, ro.cols, topo, *dictfile, r)
+	if err != nil {
+		return err
+	}
+	rng, _ := newRandom(*seed)
+
+	stats := collectStats(bs, ro.roll, rng, *n, *workers)
+	if *top > 0 && len(stats.WordFrequency) > *top {
+		stats.WordFrequency = stats.WordFrequency[:*top]
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	return stats.writeCSV(os.Stdout)
+}
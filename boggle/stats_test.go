@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	s := summarize([]int{5, 1, 4, 2, 3, 6, 7, 8, 9, 10})
+	expected := summary{Mean: 5.5, Min: 1, P10: 1, P25: 3, Median: 5, P75: 8, P90: 9, Max: 10}
+	expected.StdDev = s.StdDev
+	if s != expected {
+		t.Errorf("summary %+v, expected %+v", s, expected)
+	}
+	if s.StdDev < 2.87 || s.StdDev > 2.88 {
+		t.Errorf("standard deviation %f, expected 2.872", s.StdDev)
+	}
+}
+
+func TestCollectStats(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-common.txt")
+	bs, err := newSolver(4, 4, GridTopology, dictfile, ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same seed gives the same statistics however many workers solve the boards
+	var runs []*boardStats
+	for _, workers := range []int{1, 4} {
+		runs = append(runs, collectStats(bs, NewBoggleBoard, rand.New(rand.NewSource(5)), 50, workers))
+	}
+	if !reflect.DeepEqual(runs[0], runs[1]) {
+		t.Error("statistics depend on the number of workers")
+	}
+
+	stats := runs[0]
+	if stats.Boards != 50 || stats.Score.Min > stats.Score.Median || stats.Score.Median > stats.Score.Max {
+		t.Errorf("inconsistent score summary %+v", stats.Score)
+	}
+	if len(stats.LongestWord) != stats.Longest.Max {
+		t.Errorf("longest word %s does not have %d letters", stats.LongestWord, stats.Longest.Max)
+	}
+	for i, f := range stats.WordFrequency {
+		if f.Boards < 1 || f.Boards > 50 || f.Probability != float64(f.Boards)/50 {
+			t.Errorf("word %s appears on %d boards with probability %f", f.Word, f.Boards, f.Probability)
+		}
+		if i > 0 && f.Boards > stats.WordFrequency[i-1].Boards {
+			t.Errorf("word %s is more frequent than %s", f.Word, stats.WordFrequency[i-1].Word)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := stats.writeCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	tables := strings.Split(buf.String(), "\n\n")
+	if len(tables) != 2 || !strings.HasPrefix(tables[0], "statistic,") || !strings.HasPrefix(tables[1], "word,boards,probability\n") {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"sync"
+)
+
+// dihedral returns the rotations and reflections of a rows-by-cols board as maps from each cell to its image,
+// the identity first.  Square boards have eight and other boards four.
+func dihedral(rows, cols int) []func(i, j int) (int, int) {
+	transforms := []func(i, j int) (int, int){
+		func(i, j int) (int, int) { return i, j },
+		func(i, j int) (int, int) { return rows - 1 - i, cols - 1 - j },
+		func(i, j int) (int, int) { return rows - 1 - i, j },
+		func(i, j int) (int, int) { return i, cols - 1 - j },
+	}
+	if rows == cols {
+		transforms = append(transforms,
+			func(i, j int) (int, int) { return j, i },
+			func(i, j int) (int, int) { return cols - 1 - j, i },
+			func(i, j int) (int, int) { return j, rows - 1 - i },
+			func(i, j int) (int, int) { return cols - 1 - j, rows - 1 - i },
+		)
+	}
+	return transforms
+}
+
+// canonicalizer finds the canonical form of boards: the lexicographically least of the boards made by the
+// symmetries of the board's topology, comparing cells in row-major order.  Boards with the same canonical form
+// have the same words and score.
+type canonicalizer struct {
+	rows, cols int
+	// perms lists each symmetry as the cell whose tile moves to each cell, the identity first
+	perms [][]int
+}
+
+// newCanonicalizer finds the symmetries of a topology among the rotations and reflections of the board,
+// combined with every translation that wraps around the edges.  Only those that keep every pair of adjacent
+// cells adjacent are symmetries, so a grid has its rotations and reflections, a torus has those and its
+// translations too, and other topologies have whichever of them happen to fit.
+func newCanonicalizer(rows, cols int, adjList [][]int) *canonicalizer {
+	n := rows * cols
+	adjacent := make([]map[int]bool, n)
+	for p, adj := range adjList {
+		adjacent[p] = make(map[int]bool)
+		for _, q := range adj {
+			adjacent[p][q] = true
+		}
+	}
+
+	c := &canonicalizer{rows: rows, cols: cols}
+	seen := make(map[string]bool)
+	for dr := 0; dr < rows; dr++ {
+		for dc := 0; dc < cols; dc++ {
+			for _, t := range dihedral(rows, cols) {
+				perm := make([]int, n)
+				for i := 0; i < rows; i++ {
+					for j := 0; j < cols; j++ {
+						ti, tj := t((i+dr)%rows, (j+dc)%cols)
+						perm[ti*cols+tj] = i*cols + j
+					}
+				}
+				key := string(intsKey(perm))
+				if seen[key] || !preservesAdjacency(perm, adjacent) {
+					continue
+				}
+				seen[key] = true
+				c.perms = append(c.perms, perm)
+			}
+		}
+	}
+	return c
+}
+
+// preservesAdjacency reports whether moving tiles by perm keeps exactly the same pairs of cells adjacent
+func preservesAdjacency(perm []int, adjacent []map[int]bool) bool {
+	for p := range perm {
+		if len(adjacent[p]) != len(adjacent[perm[p]]) {
+			return false
+		}
+		for q := range adjacent[p] {
+			if !adjacent[perm[p]][perm[q]] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func intsKey(perm []int) []byte {
+	key := make([]byte, 0, 2*len(perm))
+	for _, p := range perm {
+		key = append(key, byte(p), byte(p>>8))
+	}
+	return key
+}
+
+// least returns the symmetry that makes the least board
+func (c *canonicalizer) least(bb Boggler) []int {
+	best := c.perms[0]
+	for _, perm := range c.perms[1:] {
+		for p := range perm {
+			a, b := bb.GetLinear(perm[p]), bb.GetLinear(best[p])
+			if a != b {
+				if a < b {
+					best = perm
+				}
+				break
+			}
+		}
+	}
+	return best
+}
+
+// Canonical returns the canonical form of the board
+func (c *canonicalizer) Canonical(bb Boggler) *BoggleBoard {
+	perm := c.least(bb)
+	board := make([][]string, c.rows)
+	for i := range board {
+		board[i] = make([]string, c.cols)
+		for j := range board[i] {
+			board[i][j] = bb.GetLinear(perm[i*c.cols+j])
+		}
+	}
+	return &BoggleBoard{rows: c.rows, cols: c.cols, board: board}
+}
+
+// Hash returns a 64-bit FNV-1a hash of the board's canonical form, which is the same for every symmetric board
+func (c *canonicalizer) Hash(bb Boggler) uint64 {
+	const offset, prime = 14695981039346656037, 1099511628211
+	h := uint64(offset)
+	for _, p := range c.least(bb) {
+		tile := bb.GetLinear(p)
+		for i := 0; i < len(tile); i++ {
+			h ^= uint64(tile[i])
+			h *= prime
+		}
+		// A zero byte ends each tile, so that tiles of several letters cannot run together
+		h *= prime
+	}
+	return h
+}
+
+// CanonicalBoard returns the canonical form of a board under the symmetries of a topology
+func CanonicalBoard(bb Boggler, topology Topology) (*BoggleBoard, error) {
+	adjList, err := topology.AdjList(bb.Rows(), bb.Cols())
+	if err != nil {
+		return nil, err
+	}
+	return newCanonicalizer(bb.Rows(), bb.Cols(), adjList).Canonical(bb), nil
+}
+
+// scoreShards is the number of independently locked parts of a scoreCache
+const scoreShards = 64
+
+// scoreCache remembers the scores of boards by the hash of their canonical form, so that a board, or any
+// board symmetric to it, is scored only once.  It is shared by concurrent workers.  Each part of the cache
+// is emptied when it fills, so the most recently scored boards are kept.  Two boards whose hashes collide
+// would share a score, but with 64-bit hashes that is vanishingly unlikely.
+type scoreCache struct {
+	canon  *canonicalizer
+	limit  int
+	shards [scoreShards]struct {
+		sync.Mutex
+		scores       map[uint64]int
+		hits, misses int
+	}
+}
+
+// newScoreCache makes a cache holding up to limit scores, or returns nil, which caches nothing, if limit is zero
+func newScoreCache(rows, cols int, adjList [][]int, limit int) *scoreCache {
+	if limit <= 0 {
+		return nil
+	}
+	c := &scoreCache{canon: newCanonicalizer(rows, cols, adjList), limit: max(limit/scoreShards, 1)}
+	for i := range c.shards {
+		c.shards[i].scores = make(map[uint64]int)
+	}
+	return c
+}
+
+// lookup returns the key of a board and its score, if the board has been scored before
+func (c *scoreCache) lookup(bb Boggler) (uint64, int, bool) {
+	if c == nil {
+		return 0, 0, false
+	}
+	key := c.canon.Hash(bb)
+	s := &c.shards[key%scoreShards]
+	s.Lock()
+	defer s.Unlock()
+	score, ok := s.scores[key]
+	if ok {
+		s.hits++
+	} else {
+		s.misses++
+	}
+	return key, score, ok
+}
+
+// store records the score of the board with the given key
+func (c *scoreCache) store(key uint64, score int) {
+	if c == nil {
+		return
+	}
+	s := &c.shards[key%scoreShards]
+	s.Lock()
+	defer s.Unlock()
+	if len(s.scores) >= c.limit {
+		s.scores = make(map[uint64]int)
+	}
+	s.scores[key] = score
+}
+
+// score returns the score of a board, scoring it with the scorer only if it has not been scored before
+func (c *scoreCache) score(sc *scorer, bb Boggler) int {
+	key, score, ok := c.lookup(bb)
+	if !ok {
+		score = sc.score(bb)
+		c.store(key, score)
+	}
+	return score
+}
+
+// hitRate returns the fraction of lookups that found a score
+func (c *scoreCache) hitRate() float64 {
+	if c == nil {
+		return 0
+	}
+	hits, total := 0, 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.Lock()
+		hits += s.hits
+		total += s.hits + s.misses
+		s.Unlock()
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
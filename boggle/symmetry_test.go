@@ -0,0 +1,115 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalBoard(t *testing.T) {
+	for _, tc := range []struct {
+		rows, cols int
+		topology   Topology
+		symmetries int
+	}{
+		{4, 4, GridTopology, 8},
+		{3, 4, GridTopology, 4},
+		{4, 4, TorusTopology, 128},
+		{3, 5, TorusTopology, 60},
+	} {
+		adjList, err := tc.topology.AdjList(tc.rows, tc.cols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := newCanonicalizer(tc.rows, tc.cols, adjList)
+		if len(c.perms) != tc.symmetries {
+			t.Errorf("%dx%d board has %d symmetries, expected %d", tc.rows, tc.cols, len(c.perms), tc.symmetries)
+		}
+	}
+
+	board, err := NewBoggleBoardArray([][]string{{"Qu", "I", "T"}, {"E", ".", "S"}, {"A", "B", "C"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := CanonicalBoard(board, GridTopology)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The least corner comes first, followed by the lesser of its two neighbors
+	expected := []string{"A", "B", "C", "E", ".", "S", "Qu", "I", "T"}
+	if !reflect.DeepEqual(canonical.ArrayLinear(), expected) {
+		t.Errorf("canonical form %v, expected %v", canonical.ArrayLinear(), expected)
+	}
+
+	adjList, _ := TorusTopology.AdjList(3, 3)
+	torus := newCanonicalizer(3, 3, adjList)
+	grid := newCanonicalizer(3, 3, buildAdjList(3, 3))
+	// Moving the top row to the bottom is a symmetry of the torus but not of the grid
+	shifted, _ := NewBoggleBoardArray([][]string{{"E", ".", "S"}, {"A", "B", "C"}, {"Qu", "I", "T"}})
+	for _, b := range boardSymmetries(board) {
+		if grid.Hash(b) != grid.Hash(board) || torus.Hash(b) != torus.Hash(board) {
+			t.Errorf("symmetric board %v hashes differently", b.ArrayLinear())
+		}
+	}
+	if torus.Hash(shifted) != torus.Hash(board) {
+		t.Errorf("translated board hashes differently on a torus")
+	}
+	if grid.Hash(shifted) == grid.Hash(board) {
+		t.Errorf("translated board hashes the same on a grid")
+	}
+	if !reflect.DeepEqual(torus.Canonical(shifted), torus.Canonical(board)) {
+		t.Errorf("translated board has canonical form %v on a torus, expected %v", torus.Canonical(shifted).ArrayLinear(), torus.Canonical(board).ArrayLinear())
+	}
+}
+
+func TestScoreCache(t *testing.T) {
+	dictfile := filepath.Join("dictionaries", "dictionary-common.txt")
+	bs, err := newSolver(3, 3, GridTopology, dictfile, ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board, _ := NewBoggleBoardArray([][]string{{"Qu", "I", "T"}, {"E", ".", "S"}, {"A", "B", "C"}})
+	cache := newScoreCache(3, 3, bs.adjList, 1000)
+	want := bs.score(board)
+	for _, b := range boardSymmetries(board) {
+		if s := cache.score(newScorer(bs), b); s != want {
+			t.Errorf("cached score %d, expected %d", s, want)
+		}
+	}
+	if r := cache.hitRate(); r != 7./8 {
+		t.Errorf("hit rate %f, expected 7/8", r)
+	}
+
+	var none *scoreCache
+	if s := none.score(newScorer(bs), board); s != want {
+		t.Errorf("score without a cache %d, expected %d", s, want)
+	}
+	if newScoreCache(3, 3, bs.adjList, 0) != nil {
+		t.Errorf("cache of size zero is not nil")
+	}
+}
+
+func TestTemperingCache(t *testing.T) {
+	opts, bs, freqs := testOptimizer(t)
+
+	// Remembering scores changes only the work done, not the run
+	var runs [2]*tempering
+	for i, size := range []int{0, 1 << 16} {
+		opts.cacheSize = size
+		runs[i] = newTempering(1, bs, freqs, opts, []float64{1, 10, 100}, 50)
+		for k := 0; k < 5; k++ {
+			runs[i].round()
+		}
+	}
+	for i, r := range runs[1].replicas {
+		if r.score != runs[0].replicas[i].score || !reflect.DeepEqual(r.board.ArrayLinear(), runs[0].replicas[i].board.ArrayLinear()) {
+			t.Errorf("replica %d differs with a cache", i)
+		}
+		if s := bs.score(r.board); s != r.score || r.scorer.Score() != s {
+			t.Errorf("replica %d scores %d, recorded as %d with %d from its scorer", i, s, r.score, r.scorer.Score())
+		}
+	}
+	if runs[1].cache.hitRate() == 0 {
+		t.Errorf("no scores found in the cache")
+	}
+}
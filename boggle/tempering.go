@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// replica is a single Metropolis chain of a parallel tempering run
+type replica struct {
+	rng         *rand.Rand
+	src         *splitMix
+	board       *DiceBoard
+	scorer      *incrementalScore
+	score       int
+	temperature float64
+
+	moves    int
+	accepted int
+}
+
+// step proposes a new board and accepts it according to the Metropolis criterion:
+// better boards are always accepted, and worse boards are accepted with probability exp(Δscore/T).
+// Boards whose scores are in the cache are only rescored by the incremental scorer if they are accepted.
+func (r *replica) step(bs *boggleSolver, freqs [][]float64, cache *scoreCache) {
+	last := r.board.Clone()
+	lastScore := r.score
+
+	changed := r.board.DictShuffle(r.rng, bs.adjList, freqs)
+	key, score, known := cache.lookup(r.board)
+	if !known {
+		score = r.scorer.update(r.board, changed)
+		cache.store(key, score)
+	}
+	r.score = score
+	r.moves++
+
+	if r.score >= lastScore || r.rng.Float64() < math.Exp(float64(r.score-lastScore)/r.temperature) {
+		r.accepted++
+		if known {
+			r.scorer.update(r.board, changed)
+		}
+		return
+	}
+	r.board = last.(*DiceBoard)
+	if !known {
+		r.scorer.revert()
+	}
+	r.score = lastScore
+}
+
+// tempering runs replica exchange Monte Carlo: every replica runs its own Metropolis chain at a
+// different temperature, and after every round neighboring temperatures propose to swap boards.
+// Hot replicas wander the board space freely while cold replicas refine the best boards found.
+type tempering struct {
+	solver *boggleSolver
+	freqs  [][]float64
+	rng    *rand.Rand
+	src    *splitMix
+	// cache remembers the scores of boards the replicas have visited
+	cache *scoreCache
+
+	// replicas are sorted from coldest to hottest
+	replicas []*replica
+	// steps is the number of Metropolis steps each replica takes between swap attempts
+	steps int
+
+	swaps        []int
+	swapAccepted []int
+
+	best      int
+	bestBoard []string
+}
+
+// newTempering starts a replica at each temperature from a random board.
+// Each replica draws from its own source seeded from the given seed, so a run is reproducible from the seed alone.
+func newTempering(seed int64, bs *boggleSolver, freqs [][]float64, opts optimizeOptions, temperatures []float64, steps int) *tempering {
+	t := &tempering{
+		solver:       bs,
+		freqs:        freqs,
+		replicas:     make([]*replica, len(temperatures)),
+		steps:        steps,
+		swaps:        make([]int, len(temperatures)),
+		swapAccepted: make([]int, len(temperatures)),
+		cache:        newScoreCache(opts.rows, opts.cols, bs.adjList, opts.cacheSize),
+	}
+	t.rng, t.src = newSplitMixRand(seed)
+	for i, temp := range temperatures {
+		rng, src := newSplitMixRand(t.rng.Int63())
+		board := newDiceBoard(rng, opts.rows, opts.cols, opts.dice)
+		scorer := bs.newIncremental(board, newScorer(bs))
+		t.replicas[i] = &replica{rng: rng, src: src, board: board, scorer: scorer, score: scorer.Score(), temperature: temp}
+		t.record(t.replicas[i])
+	}
+	return t
+}
+
+// restoreTempering resumes a tempering run saved with checkpoint
+func restoreTempering(cp *checkpoint, bs *boggleSolver, freqs [][]float64, opts optimizeOptions, steps int) (*tempering, error) {
+	n := len(cp.Workers)
+	if len(cp.Swaps) != n || len(cp.SwapAccepted) != n {
+		return nil, errors.New("checkpoint is not from a tempering run")
+	}
+	t := &tempering{
+		solver:       bs,
+		freqs:        freqs,
+		src:          &splitMix{state: cp.RNG},
+		replicas:     make([]*replica, n),
+		steps:        steps,
+		swaps:        cp.Swaps,
+		swapAccepted: cp.SwapAccepted,
+		best:         cp.Best,
+		bestBoard:    cp.BestBoard,
+		cache:        newScoreCache(opts.rows, opts.cols, bs.adjList, opts.cacheSize),
+	}
+	t.rng = rand.New(t.src)
+	for i, w := range cp.Workers {
+		board, err := w.Board.restore(opts.rows, opts.cols, opts.dice)
+		if err != nil {
+			return nil, err
+		}
+		if w.Temperature <= 0 || (i > 0 && w.Temperature <= cp.Workers[i-1].Temperature) {
+			return nil, errors.New("checkpoint temperatures must be positive and increasing")
+		}
+		src := &splitMix{state: w.RNG}
+		t.replicas[i] = &replica{
+			rng:         rand.New(src),
+			src:         src,
+			board:       board,
+			scorer:      bs.newIncremental(board, newScorer(bs)),
+			score:       w.Score,
+			temperature: w.Temperature,
+			moves:       w.Moves,
+			accepted:    w.Accepted,
+		}
+	}
+	return t, nil
+}
+
+// checkpoint saves the state of every replica between rounds
+func (t *tempering) checkpoint() *checkpoint {
+	cp := &checkpoint{
+		RNG:          t.src.state,
+		Best:         t.best,
+		BestBoard:    t.bestBoard,
+		Workers:      make([]workerState, len(t.replicas)),
+		Swaps:        t.swaps,
+		SwapAccepted: t.swapAccepted,
+	}
+	for i, r := range t.replicas {
+		cp.Workers[i] = workerState{
+			Board:       r.board.state(),
+			Score:       r.score,
+			RNG:         r.src.state,
+			Temperature: r.temperature,
+			Moves:       r.moves,
+			Accepted:    r.accepted,
+		}
+	}
+	return cp
+}
+
+// round runs every replica concurrently for the configured number of steps, then attempts swaps.
+// It returns true if a new best board was found.
+func (t *tempering) round() bool {
+	var wg sync.WaitGroup
+	bests := make([]boardScore, len(t.replicas))
+	for i, r := range t.replicas {
+		wg.Add(1)
+		go func(i int, r *replica) {
+			defer wg.Done()
+			for s := 0; s < t.steps; s++ {
+				r.step(t.solver, t.freqs, t.cache)
+				if r.score > bests[i].score {
+					bests[i] = boardScore{score: r.score, board: r.board.ArrayLinear()}
+				}
+			}
+		}(i, r)
+	}
+	wg.Wait()
+
+	improved := false
+	for _, b := range bests {
+		if b.score > t.best {
+			t.best = b.score
+			t.bestBoard = b.board
+			improved = true
+		}
+	}
+
+	t.exchange()
+	return improved
+}
+
+// exchange attempts to swap the boards of each pair of neighboring temperatures.
+// The swap is accepted with probability min(1, exp((s_hot - s_cold) * (1/T_cold - 1/T_hot))), which
+// keeps every chain sampling from its own temperature's distribution.
+func (t *tempering) exchange() {
+	for i := 0; i+1 < len(t.replicas); i++ {
+		cold := t.replicas[i]
+		hot := t.replicas[i+1]
+		t.swaps[i]++
+		delta := float64(hot.score-cold.score) * (1/cold.temperature - 1/hot.temperature)
+		if delta >= 0 || t.rng.Float64() < math.Exp(delta) {
+			cold.board, hot.board = hot.board, cold.board
+			cold.scorer, hot.scorer = hot.scorer, cold.scorer
+			cold.score, hot.score = hot.score, cold.score
+			t.swapAccepted[i]++
+		}
+	}
+}
+
+func (t *tempering) record(r *replica) {
+	if r.score > t.best {
+		t.best = r.score
+		t.bestBoard = r.board.ArrayLinear()
+	}
+}
+
+// acceptance returns the fraction of accepted moves at each temperature and the fraction of
+// accepted swaps between each temperature and the next hotter one
+func (t *tempering) acceptance() ([]float64, []float64) {
+	moves := make([]float64, len(t.replicas))
+	swaps := make([]float64, len(t.replicas)-1)
+	for i, r := range t.replicas {
+		if r.moves > 0 {
+			moves[i] = float64(r.accepted) / float64(r.moves)
+		}
+		if i < len(swaps) && t.swaps[i] > 0 {
+			swaps[i] = float64(t.swapAccepted[i]) / float64(t.swaps[i])
+		}
+	}
+	return moves, swaps
+}
+
+// geometricLadder returns n temperatures spaced geometrically from tmin to tmax
+func geometricLadder(tmin float64, tmax float64, n int) ([]float64, error) {
+	if n < 1 {
+		return nil, errors.New("need at least one temperature")
+	}
+	if tmin <= 0 || tmax < tmin {
+		return nil, errors.New("temperatures must satisfy 0 < tmin <= tmax")
+	}
+	ladder := make([]float64, n)
+	for i := range ladder {
+		if n == 1 {
+			ladder[i] = tmin
+			continue
+		}
+		ladder[i] = tmin * math.Pow(tmax/tmin, float64(i)/float64(n-1))
+	}
+	return ladder, nil
+}
+
+// formatLadder writes a temperature ladder in the form read by parseLadder
+func formatLadder(ladder []float64) string {
+	s := make([]string, len(ladder))
+	for i, temp := range ladder {
+		s[i] = strconv.FormatFloat(temp, 'g', -1, 64)
+	}
+	return strings.Join(s, ",")
+}
+
+// parseLadder reads a comma-separated list of increasing temperatures
+func parseLadder(text string) ([]float64, error) {
+	fields := strings.Split(text, ",")
+	ladder := make([]float64, len(fields))
+	for i, f := range fields {
+		temp, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, err
+		}
+		if temp <= 0 {
+			return nil, errors.New("temperatures must be positive")
+		}
+		if i > 0 && temp <= ladder[i-1] {
+			return nil, errors.New("temperatures must be listed in increasing order")
+		}
+		ladder[i] = temp
+	}
+	return ladder, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLadders(t *testing.T) {
+	ladder, err := geometricLadder(1, 100, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range []float64{1, 10, 100} {
+		if math.Abs(ladder[i]-e) > 1e-9 {
+			t.Errorf("temperature %d = %f, expected %f", i, ladder[i], e)
+		}
+	}
+
+	if _, err := parseLadder("1, 2.5,10"); err != nil {
+		t.Error(err)
+	}
+	for _, bad := range []string{"", "1,x", "0,1", "2,1"} {
+		if _, err := parseLadder(bad); err == nil {
+			t.Errorf("ladder %q parsed without error", bad)
+		}
+	}
+}
+
+// testOptimizer returns the options, solver, and letter frequencies the optimizer tests share: a 4x4 grid of
+// the 1992 dice, scored by the classic rule over the common dictionary
+func testOptimizer(t *testing.T) (optimizeOptions, *boggleSolver, [][]float64) {
+	t.Helper()
+	dictfile := filepath.Join("dictionaries", "dictionary-common.txt")
+	opts := optimizeOptions{rows: 4, cols: 4, topology: GridTopology, dice: diceSets["1992"], dictfile: dictfile, rule: ClassicRule}
+	bs, err := newSolver(opts.rows, opts.cols, opts.topology, opts.dictfile, opts.rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	freqs, err := frequencyCount(dictfile, opts.rule.MinLength(), opts.rows*opts.cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return opts, bs, freqs
+}
+
+func TestTempering(t *testing.T) {
+	opts, bs, freqs := testOptimizer(t)
+
+	tp := newTempering(1, bs, freqs, opts, []float64{1, 10, 100}, 20)
+	for i := 0; i < 5; i++ {
+		tp.round()
+	}
+
+	for _, r := range tp.replicas {
+		if r.moves != 100 {
+			t.Errorf("replica at T=%f made %d moves, expected 100", r.temperature, r.moves)
+		}
+		if s := bs.score(r.board); s != r.score {
+			t.Errorf("replica at T=%f has score %d, but its board scores %d", r.temperature, r.score, s)
+		}
+		if r.score > tp.best {
+			t.Errorf("replica score %d exceeds best %d", r.score, tp.best)
+		}
+	}
+	if tp.swaps[0] != 5 {
+		t.Errorf("%d swaps attempted, expected 5", tp.swaps[0])
+	}
+}
+
+func TestTemperingReproducible(t *testing.T) {
+	opts, bs, freqs := testOptimizer(t)
+
+	var boards [2][]string
+	for i := range boards {
+		tp := newTempering(8675309, bs, freqs, opts, []float64{1, 10, 100}, 10)
+		for r := 0; r < 5; r++ {
+			tp.round()
+		}
+		boards[i] = tp.replicas[0].board.ArrayLinear()
+	}
+	if !reflect.DeepEqual(boards[0], boards[1]) {
+		t.Errorf("runs with the same seed ended on different boards %v and %v", boards[0], boards[1])
+	}
+}
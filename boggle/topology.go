@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Topology decides which cells of a board are adjacent to one another.
+// Cells are numbered in row-major order, matching Boggler's GetLinear.
+type Topology interface {
+	// AdjList returns the cells adjacent to each cell of a rows-by-cols board
+	AdjList(rows, cols int) ([][]int, error)
+}
+
+// GridTopology is the standard board, where each cell touches its horizontal, vertical, and diagonal neighbors
+var GridTopology Topology = gridTopology{}
+
+// TorusTopology is a grid that wraps around from the top to the bottom and from the left to the right
+var TorusTopology Topology = torusTopology{}
+
+// HexTopology is a grid of hexagons in "odd-r" layout, where odd rows are shifted half a cell to the right
+var HexTopology Topology = hexTopology{}
+
+// CubeTopology is an n-by-n-by-n cube of cells, each touching all of its neighbors in three dimensions.
+// The board text stacks the n layers of the cube vertically, so the board has n*n rows and n columns.
+var CubeTopology Topology = cubeTopology{}
+
+type gridTopology struct{}
+
+// AdjList implements Topology's interface
+func (gridTopology) AdjList(rows, cols int) ([][]int, error) {
+	return buildAdjList(rows, cols), nil
+}
+
+type torusTopology struct{}
+
+// AdjList implements Topology's interface
+func (torusTopology) AdjList(rows, cols int) ([][]int, error) {
+	ret := make([][]int, rows*cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			i := r*cols + c
+			ret[i] = make([]int, 0)
+			for _, deltar := range []int{-1, 0, 1} {
+				for _, deltac := range []int{-1, 0, 1} {
+					target := ((r+deltar+rows)%rows)*cols + (c+deltac+cols)%cols
+					ret[i] = appendNeighbor(ret[i], i, target)
+				}
+			}
+		}
+	}
+	return ret, nil
+}
+
+type hexTopology struct{}
+
+// AdjList implements Topology's interface
+func (hexTopology) AdjList(rows, cols int) ([][]int, error) {
+	ret := make([][]int, rows*cols)
+	for r := 0; r < rows; r++ {
+		// Diagonal neighbors are up-left and up-right of the cell on even rows, and shifted one column right on odd rows
+		shift := r % 2
+		deltas := [][2]int{{0, -1}, {0, 1}, {-1, shift - 1}, {-1, shift}, {1, shift - 1}, {1, shift}}
+		for c := 0; c < cols; c++ {
+			i := r*cols + c
+			ret[i] = make([]int, 0)
+			for _, d := range deltas {
+				targetr := r + d[0]
+				targetc := c + d[1]
+				if targetr < 0 || targetr >= rows || targetc < 0 || targetc >= cols {
+					continue
+				}
+				ret[i] = append(ret[i], targetr*cols+targetc)
+			}
+		}
+	}
+	return ret, nil
+}
+
+type cubeTopology struct{}
+
+// AdjList implements Topology's interface
+func (cubeTopology) AdjList(rows, cols int) ([][]int, error) {
+	n := cols
+	if rows != n*n {
+		return nil, fmt.Errorf("a cube with sides of %d cells needs a board with %d rows, not %d", n, n*n, rows)
+	}
+	ret := make([][]int, rows*cols)
+	for z := 0; z < n; z++ {
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				i := (z*n+y)*n + x
+				ret[i] = make([]int, 0)
+				for dz := -1; dz <= 1; dz++ {
+					for dy := -1; dy <= 1; dy++ {
+						for dx := -1; dx <= 1; dx++ {
+							tz, ty, tx := z+dz, y+dy, x+dx
+							if tz < 0 || tz >= n || ty < 0 || ty >= n || tx < 0 || tx >= n {
+								continue
+							}
+							ret[i] = appendNeighbor(ret[i], i, (tz*n+ty)*n+tx)
+						}
+					}
+				}
+			}
+		}
+	}
+	return ret, nil
+}
+
+// appendNeighbor adds target to the neighbors of cell i unless it is the cell itself or already present.
+// Small wrapped boards can reach the same neighbor in more than one direction.
+func appendNeighbor(adj []int, i int, target int) []int {
+	if target == i || isNeighbor(adj, target) {
+		return adj
+	}
+	return append(adj, target)
+}
+
+// isNeighbor reports whether target is among the neighbors adj
+func isNeighbor(adj []int, target int) bool {
+	for _, a := range adj {
+		if a == target {
+			return true
+		}
+	}
+	return false
+}
+
+// fileTopology is an arbitrary adjacency read from a file
+type fileTopology struct {
+	filename string
+	adjList  [][]int
+}
+
+// ReadTopology reads an arbitrary adjacency from a file.
+// Each non-blank line holds a cell number, a colon, and the whitespace-separated numbers of the cells adjacent to it,
+// for example "5: 0 1 2 4 6 8 9 10".  Cells are numbered from zero in row-major order, and lines beginning with '#' are ignored.
+// Every cell must have a neighbor, and every cell must list each cell that lists it.
+func ReadTopology(filename string) (Topology, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	adj := make(map[int][]int)
+	// lines holds the line each cell is listed on, for errors
+	lines := make(map[int]int)
+	n := 0
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.SplitN(text, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected cell, colon, and neighbors, got %q", filename, line, text)
+		}
+		cell, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || cell < 0 {
+			return nil, fmt.Errorf("%s:%d: invalid cell %q", filename, line, parts[0])
+		}
+		if _, ok := adj[cell]; ok {
+			return nil, fmt.Errorf("%s:%d: cell %d listed twice", filename, line, cell)
+		}
+		adj[cell] = make([]int, 0)
+		lines[cell] = line
+		if cell >= n {
+			n = cell + 1
+		}
+		for _, f := range strings.Fields(parts[1]) {
+			target, err := strconv.Atoi(f)
+			if err != nil || target < 0 {
+				return nil, fmt.Errorf("%s:%d: invalid neighbor %q", filename, line, f)
+			}
+			adj[cell] = appendNeighbor(adj[cell], cell, target)
+			if target >= n {
+				n = target + 1
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	adjList := make([][]int, n)
+	for i := range adjList {
+		if len(adj[i]) == 0 {
+			if _, ok := lines[i]; !ok {
+				return nil, fmt.Errorf("%s: cell %d is not listed", filename, i)
+			}
+			return nil, fmt.Errorf("%s:%d: cell %d has no neighbors", filename, lines[i], i)
+		}
+		for _, j := range adj[i] {
+			if !isNeighbor(adj[j], i) {
+				return nil, fmt.Errorf("%s:%d: cell %d lists %d as a neighbor, but %d does not list %d", filename, lines[i], i, j, j, i)
+			}
+		}
+		adjList[i] = adj[i]
+	}
+	return &fileTopology{filename: filename, adjList: adjList}, nil
+}
+
+// AdjList implements Topology's interface
+func (ft *fileTopology) AdjList(rows, cols int) ([][]int, error) {
+	if len(ft.adjList) != rows*cols {
+		return nil, fmt.Errorf("%s describes %d cells, but a %d-by-%d board has %d", ft.filename, len(ft.adjList), rows, cols, rows*cols)
+	}
+	ret := make([][]int, len(ft.adjList))
+	for i, adj := range ft.adjList {
+		ret[i] = make([]int, len(adj))
+		copy(ret[i], adj)
+	}
+	return ret, nil
+}
+
+// LoadTopology returns the built-in topology with the given name ("grid", "torus", "hex", or "cube"),
+// or reads an adjacency file with ReadTopology if there is no such topology
+func LoadTopology(name string) (Topology, error) {
+	switch strings.ToLower(name) {
+	case "grid":
+		return GridTopology, nil
+	case "torus":
+		return TorusTopology, nil
+	case "hex":
+		return HexTopology, nil
+	case "cube":
+		return CubeTopology, nil
+	default:
+		return ReadTopology(name)
+	}
+}
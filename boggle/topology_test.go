@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func degrees(t *testing.T, topo Topology, rows, cols int) []int {
+	adjList, err := topo.AdjList(rows, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(adjList) != rows*cols {
+		t.Fatalf("%d cells in adjacency list, expected %d", len(adjList), rows*cols)
+	}
+	d := make([]int, len(adjList))
+	for i, adj := range adjList {
+		d[i] = len(adj)
+		for _, a := range adj {
+			if a == i {
+				t.Errorf("cell %d is adjacent to itself", i)
+			}
+		}
+	}
+	return d
+}
+
+func TestTopologies(t *testing.T) {
+	tests := []struct {
+		name       string
+		topo       Topology
+		rows, cols int
+		cells      []int
+		expected   []int
+	}{
+		{"grid", GridTopology, 4, 4, []int{0, 1, 5, 15}, []int{3, 5, 8, 3}},
+		{"torus", TorusTopology, 4, 4, []int{0, 1, 5, 15}, []int{8, 8, 8, 8}},
+		{"small torus", TorusTopology, 2, 3, []int{0, 4}, []int{5, 5}},
+		{"hex", HexTopology, 4, 4, []int{0, 3, 5, 6, 7}, []int{2, 3, 6, 6, 3}},
+		{"cube", CubeTopology, 16, 4, []int{0, 21, 63}, []int{7, 26, 7}},
+	}
+	for _, tt := range tests {
+		d := degrees(t, tt.topo, tt.rows, tt.cols)
+		for i, c := range tt.cells {
+			if d[c] != tt.expected[i] {
+				t.Errorf("%s: cell %d has %d neighbors, expected %d", tt.name, c, d[c], tt.expected[i])
+			}
+		}
+	}
+
+	if _, err := CubeTopology.AdjList(4, 4); err == nil {
+		t.Error("cube topology accepted a 4-by-4 board")
+	}
+}
+
+func TestReadTopology(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "ring.txt")
+	ring := "# a ring of four cells\n0: 1 3\n1: 0 2\n2: 1 3\n3: 2 0\n"
+	if err := ioutil.WriteFile(fn, []byte(ring), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	topo, err := LoadTopology(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, d := range degrees(t, topo, 2, 2) {
+		if d != 2 {
+			t.Errorf("cell %d has %d neighbors, expected 2", i, d)
+		}
+	}
+	if _, err := topo.AdjList(2, 3); err == nil {
+		t.Error("four-cell topology accepted a 2-by-3 board")
+	}
+
+	// On the ring, cells 1 and 3 are not adjacent, so ABD cannot be spelled
+	dictfile := filepath.Join(t.TempDir(), "dictionary.txt")
+	if err := ioutil.WriteFile(dictfile, []byte("abc\nabd\nadc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	board, err := NewBoggleBoardArray([][]string{{"A", "B"}, {"C", "D"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(2, 2, topo, dictfile, ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := bs.score(board); s != 2 {
+		t.Errorf("score %d on ring, expected 2", s)
+	}
+
+	// Cells without neighbors and neighbors that are not mutual are rejected at the line that lists them
+	for text, expected := range map[string]string{
+		"0: 1\n1: 0\n2:\n3: 2\n":           ":3: cell 2 has no neighbors",
+		"0: 1 2\n1: 0\n2: 3\n3: 2\n":       ":1: cell 0 lists 2 as a neighbor, but 2 does not list 0",
+		"0: 1\n1: 0 2\n2: 1\n4: 5\n5: 4\n": ": cell 3 is not listed",
+	} {
+		if err := ioutil.WriteFile(fn, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTopology(fn); err == nil || !strings.HasSuffix(err.Error(), expected) {
+			t.Errorf("topology %q loaded with error %v, expected %q", text, err, expected)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+#!/usr/bin/python3
+
+# Draw the path of a word on a board solved with `boggle solve -json`.
+# usage: ./paths.py solution.json WORD
+
+import json
+import sys
+import matplotlib.pyplot as plt
+
+with open(sys.argv[1]) as f:
+    soln = json.load(f)
+word = sys.argv[2].upper()
+
+rows, cols = soln['rows'], soln['cols']
+found = [w for w in soln['words'] if w['word'] == word]
+if not found:
+    sys.exit("{} not found on board".format(word))
+
+plt.figure(0, figsize=(cols, rows))
+for i, letter in enumerate(soln['board']):
+    plt.text(i % cols, i // cols, letter, ha='center', va='center', fontsize=20)
+
+path = found[0]['path']
+x = [c['col'] for c in path]
+y = [c['row'] for c in path]
+plt.plot(x, y, color="#c05131", lw=4, alpha=.5)
+plt.plot(x[0], y[0], 'o', color="#c05131", ms=20, alpha=.5)
+
+plt.xlim(-.5, cols - .5)
+plt.ylim(rows - .5, -.5)
+plt.axis('off')
+plt.title("{} ({} points)".format(word, found[0]['score']))
+
+plt.savefig('{}.png'.format(word.lower()))
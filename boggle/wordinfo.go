@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// rareFlag is the flag given to words rarer than the frequency set by -rare-below
+const rareFlag = "rare"
+
+// wordInfo is what is known about a word beyond its spelling
+type wordInfo struct {
+	// frequency is the number of times the word appears per million words of some corpus, or zero if unknown
+	frequency float64
+	// flags mark words to be treated differently, such as obscure or offensive words
+	flags map[string]bool
+	// sources name the dictionaries and word lists that hold the word
+	sources map[string]bool
+}
+
+// WordMetadata holds per-word metadata read from side files, alongside a dictionary that holds only the words
+type WordMetadata struct {
+	words map[string]*wordInfo
+}
+
+func newWordMetadata() *WordMetadata {
+	return &WordMetadata{words: make(map[string]*wordInfo)}
+}
+
+// info returns the metadata of a word, adding an empty entry if there is none
+func (m *WordMetadata) info(word string) *wordInfo {
+	wi, ok := m.words[word]
+	if !ok {
+		wi = &wordInfo{flags: make(map[string]bool), sources: make(map[string]bool)}
+		m.words[word] = wi
+	}
+	return wi
+}
+
+// ReadWordInfo adds the metadata in a file.  Each non-blank line holds a word followed by any of the fields
+// freq=N (occurrences per million words), flags=a,b,... and sources=a,b,...  A later frequency for a word
+// replaces an earlier one, and flags and sources accumulate.  Lines beginning with '#' are ignored, as are
+// words that cannot be spelled in the letters A through Z, since no dictionary holds them.
+func (m *WordMetadata) ReadWordInfo(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		word, _, _, reason := normalizeWord(fields[0], defaultWordListOptions)
+		if reason != keepWord || word == "" {
+			continue
+		}
+		wi := m.info(word)
+		for _, f := range fields[1:] {
+			key, value, ok := strings.Cut(f, "=")
+			if !ok {
+				return fmt.Errorf("%s:%d: expected key=value, got %q", filename, line, f)
+			}
+			switch key {
+			case "freq":
+				freq, err := strconv.ParseFloat(value, 64)
+				if err != nil || freq < 0 || math.IsInf(freq, 0) {
+					return fmt.Errorf("%s:%d: invalid frequency %q", filename, line, value)
+				}
+				wi.frequency = freq
+			case "flags":
+				addTags(wi.flags, value)
+			case "sources":
+				addTags(wi.sources, value)
+			default:
+				return fmt.Errorf("%s:%d: unknown field %q (freq, flags, or sources)", filename, line, key)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	return nil
+}
+
+// ReadTier marks every word in a word list as coming from it, naming the source after the file without its
+// extension, so that dictionary-common.txt makes the source dictionary-common
+func (m *WordMetadata) ReadTier(filename string) error {
+	words, err := readWordList(filename)
+	if err != nil {
+		return err
+	}
+	source := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	for _, w := range words {
+		m.info(w).sources[source] = true
+	}
+	return nil
+}
+
+// addTags adds the names in a comma-separated list to a set
+func addTags(set map[string]bool, list string) {
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			set[t] = true
+		}
+	}
+}
+
+// weightedRule scores a word by another rule, then excludes or weights it by its metadata.
+// A word not listed in the metadata has no frequency, flags, or sources.
+type weightedRule struct {
+	base ScoringRule
+	meta *WordMetadata
+	// only, if not nil, lists the sources of the only words that score
+	only map[string]bool
+	// exclude lists flags that keep a word from scoring
+	exclude map[string]bool
+	// rareBelow flags words appearing fewer times per million as rare; zero flags none
+	rareBelow float64
+	// weights multiply the points of words with each flag or source
+	weights map[string]float64
+}
+
+// MinLength implements ScoringRule's interface
+func (wr *weightedRule) MinLength() int {
+	return wr.base.MinLength()
+}
+
+// Score implements ScoringRule's interface.  The points of the base rule are multiplied by the weight of
+// every flag and source the word has and rounded to the nearest whole number.
+func (wr *weightedRule) Score(word string) int {
+	points := wr.base.Score(word)
+	if points == 0 {
+		return 0
+	}
+	wi := wr.meta.words[word]
+	if wi == nil {
+		wi = &wordInfo{}
+	}
+	if wr.only != nil && !hasAny(wi.sources, wr.only) {
+		return 0
+	}
+	// Words of unknown frequency are taken to be rare
+	rare := wr.rareBelow > 0 && wi.frequency < wr.rareBelow
+	if hasAny(wi.flags, wr.exclude) || (rare && wr.exclude[rareFlag]) {
+		return 0
+	}
+
+	weight := 1.
+	for _, tags := range []map[string]bool{wi.flags, wi.sources} {
+		for t := range tags {
+			if w, ok := wr.weights[t]; ok {
+				weight *= w
+			}
+		}
+	}
+	if w, ok := wr.weights[rareFlag]; ok && rare {
+		weight *= w
+	}
+	return int(math.Round(float64(points) * weight))
+}
+
+// hasAny reports whether the two sets share a member
+func hasAny(a, b map[string]bool) bool {
+	for t := range a {
+		if b[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWeights reads a comma-separated list of flag or source names with their weights, as obscure=0.5
+func parseWeights(text string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, f := range strings.Split(text, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(f, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=weight, got %q", f)
+		}
+		w, err := strconv.ParseFloat(value, 64)
+		if err != nil || w < 0 || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("invalid weight %q for %s", value, name)
+		}
+		weights[name] = w
+	}
+	return weights, nil
+}
+
+// wordInfoFlags are the flags of scoringFlags that weight words by their metadata
+type wordInfoFlags struct {
+	infoFiles *string
+	tierFiles *string
+	only      *string
+	exclude   *string
+	rareBelow *float64
+	weights   *string
+}
+
+func newWordInfoFlags(fs *flag.FlagSet) *wordInfoFlags {
+	return &wordInfoFlags{
+		infoFiles: fs.String("word-info", "", "comma-separated files of word metadata: lines of word freq=N flags=a,b sources=a,b"),
+		tierFiles: fs.String("tier", "", "comma-separated word lists; each word is given the list's file name, without extension, as a source"),
+		only:      fs.String("only", "", "comma-separated sources; only words from one of them score"),
+		exclude:   fs.String("exclude", "", "comma-separated flags, such as obscure,offensive,rare; words with any of them do not score"),
+		rareBelow: fs.Float64("rare-below", 0, "flag words appearing fewer times per million than this, or of unknown frequency, as rare (0 flags none)"),
+		weights:   fs.String("weight", "", "comma-separated flag or source weights multiplying the points of words having them, as obscure=0.5,rare=0"),
+	}
+}
+
+// set reports whether any of the flags were given
+func (f *wordInfoFlags) set() bool {
+	return *f.infoFiles != "" || *f.tierFiles != "" || *f.only != "" || *f.exclude != "" || *f.rareBelow != 0 || *f.weights != ""
+}
+
+// rule wraps a scoring rule with the weighting the flags describe, loading the metadata files
+func (f *wordInfoFlags) rule(base ScoringRule) (ScoringRule, error) {
+	if !f.set() {
+		return base, nil
+	}
+	if *f.rareBelow < 0 {
+		return nil, fmt.Errorf("rare-below must not be negative")
+	}
+	wr := &weightedRule{base: base, meta: newWordMetadata(), exclude: make(map[string]bool), rareBelow: *f.rareBelow}
+	for _, fn := range splitList(*f.infoFiles) {
+		if err := wr.meta.ReadWordInfo(fn); err != nil {
+			return nil, err
+		}
+	}
+	for _, fn := range splitList(*f.tierFiles) {
+		if err := wr.meta.ReadTier(fn); err != nil {
+			return nil, err
+		}
+	}
+	if *f.only != "" {
+		wr.only = make(map[string]bool)
+		addTags(wr.only, *f.only)
+	}
+	addTags(wr.exclude, *f.exclude)
+	var err error
+	if wr.weights, err = parseWeights(*f.weights); err != nil {
+		return nil, err
+	}
+	return wr, nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(text string) []string {
+	var list []string
+	for _, s := range strings.Split(text, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeightedRule(t *testing.T) {
+	dir := t.TempDir()
+	infofile := filepath.Join(dir, "info.txt")
+	tierfile := filepath.Join(dir, "casual.txt")
+	info := "# word metadata\nQuite freq=120 sources=twl06\nquiet freq=40\nquire freq=0.3 flags=obscure\nQUITS flags=offensive,obscure\nsuite freq=2\n"
+	if err := ioutil.WriteFile(infofile, []byte(info), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(tierfile, []byte("quite\nquiet\nsuite\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		args   []string
+		scores map[string]int
+	}{
+		{nil, map[string]int{"QUITE": 2, "QUIRE": 2, "QUITS": 2, "TIES": 1}},
+		{[]string{"-only", "casual"}, map[string]int{"QUITE": 2, "QUIET": 2, "QUIRE": 0, "TIES": 0}},
+		{[]string{"-exclude", "obscure"}, map[string]int{"QUITE": 2, "QUIRE": 0, "QUITS": 0, "TIES": 1}},
+		{[]string{"-rare-below", "10", "-exclude", "rare"}, map[string]int{"QUITE": 2, "QUIET": 2, "SUITE": 0, "QUIRE": 0, "TIES": 0}},
+		{[]string{"-rare-below", "10", "-weight", "rare=0.5,twl06=3"}, map[string]int{"QUITE": 6, "QUIET": 2, "SUITE": 1, "TIES": 1}},
+		{[]string{"-weight", "obscure=0.25,offensive=0"}, map[string]int{"QUIRE": 1, "QUITS": 0}},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		rule := scoringFlags(fs)
+		if err := fs.Parse(append([]string{"-word-info", infofile, "-tier", tierfile}, tc.args...)); err != nil {
+			t.Fatal(err)
+		}
+		r, err := rule()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.MinLength() != 3 {
+			t.Errorf("%v: minimum length %d", tc.args, r.MinLength())
+		}
+		for word, e := range tc.scores {
+			if s := r.Score(word); s != e {
+				t.Errorf("%v: %s scores %d, expected %d", tc.args, word, s, e)
+			}
+		}
+	}
+
+	// Without metadata flags the rule is unchanged
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	rule := scoringFlags(fs)
+	fs.Parse(nil)
+	if r, _ := rule(); r != ClassicRule {
+		t.Errorf("rule without metadata is %v", r)
+	}
+
+	for _, bad := range []string{"quite freq=x\n", "quite freq=-1\n", "quite rank=3\n", "quite obscure\n"} {
+		fn := filepath.Join(dir, "bad.txt")
+		if err := ioutil.WriteFile(fn, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := newWordMetadata().ReadWordInfo(fn); err == nil {
+			t.Errorf("read %q without error", bad)
+		}
+	}
+	for _, bad := range []string{"obscure", "obscure=-1", "rare=x"} {
+		if _, err := parseWeights(bad); err == nil {
+			t.Errorf("parsed weights %q without error", bad)
+		}
+	}
+}
+
+func TestCommonWordsOnly(t *testing.T) {
+	common := filepath.Join("dictionaries", "dictionary-common.txt")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	rule := scoringFlags(fs)
+	fs.Parse([]string{"-tier", common, "-only", "dictionary-common"})
+	r, err := rule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	board, err := ReadBoggleBoard(filepath.Join("test", "board-points100.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := newSolver(board.Rows(), board.Cols(), GridTopology, defaultDictionary, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	everything, err := newSolver(board.Rows(), board.Cols(), GridTopology, defaultDictionary, ClassicRule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, err := readWordSet(common)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dictionary word that is not common is on the board but does not score
+	for _, tc := range []struct{ word, reason string }{
+		{"ROM", "does not score under the chosen rules"},
+		{"OMM", "not in the dictionary"},
+	} {
+		if _, err := bs.checkWord(board, tc.word); rejectReason(err) != tc.reason {
+			t.Errorf("%s rejected as %v, expected %q", tc.word, err, tc.reason)
+		}
+	}
+
+	sol := bs.findWords(board, false)
+	for _, w := range sol.Words {
+		if !words[w.Word] {
+			t.Errorf("%s scored but is not a common word", w.Word)
+		}
+	}
+	if len(sol.Words) == 0 || sol.Score >= everything.score(board) {
+		t.Errorf("common words score %d of %d", sol.Score, everything.score(board))
+	}
+}
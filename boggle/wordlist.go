@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// wordListOptions controls how the lines of a word list are normalized and which words are kept.
+// Words are always uppercased and must end up made entirely of the letters A through Z.
+type wordListOptions struct {
+	// Transliterate replaces accented and other non-ASCII letters with their closest ASCII spelling (É becomes E, ß becomes SS);
+	// otherwise words containing them are dropped
+	Transliterate bool
+	// StripPunctuation removes hyphens and apostrophes from words (O'CLOCK becomes OCLOCK); otherwise words containing them
+	// are dropped, as the official rules do not allow them
+	StripPunctuation bool
+	// MinLength and MaxLength limit the length of words kept; zero is no limit
+	MinLength int
+	MaxLength int
+	// Allow, if not nil, lists the only words kept; Block lists words always dropped.  Both hold normalized words.
+	Allow map[string]bool
+	Block map[string]bool
+}
+
+// defaultWordListOptions are used to load every dictionary given to the solver
+var defaultWordListOptions = wordListOptions{Transliterate: true}
+
+// wordListStats counts what happened to each line of a word list
+type wordListStats struct {
+	Lines          int `json:"lines"`
+	Blank          int `json:"blank"`
+	Comments       int `json:"comments"`
+	Kept           int `json:"kept"`
+	Duplicates     int `json:"duplicates"`
+	Transliterated int `json:"transliterated"`
+	Stripped       int `json:"stripped"`
+	// Dropped words, by reason
+	Punctuation int `json:"punctuation"`
+	NonASCII    int `json:"non_ascii"`
+	NonLetters  int `json:"non_letters"`
+	TooShort    int `json:"too_short"`
+	TooLong     int `json:"too_long"`
+	Blocked     int `json:"blocked"`
+	NotAllowed  int `json:"not_allowed"`
+}
+
+func (s wordListStats) String() string {
+	return fmt.Sprintf("%d lines: %d words kept (%d transliterated, %d with punctuation removed), %d duplicates, %d blank, %d comments; "+
+		"dropped %d with punctuation, %d with non-ASCII letters, %d with other characters, %d too short, %d too long, %d blocked, %d not allowed",
+		s.Lines, s.Kept, s.Transliterated, s.Stripped, s.Duplicates, s.Blank, s.Comments,
+		s.Punctuation, s.NonASCII, s.NonLetters, s.TooShort, s.TooLong, s.Blocked, s.NotAllowed)
+}
+
+// transliterations spells non-ASCII letters in ASCII.  Letters are looked up after uppercasing.
+var transliterations = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Ā': "A", 'Ă': "A", 'Ą': "A",
+	'Æ': "AE", 'Ç': "C", 'Ć': "C", 'Č': "C", 'Ď': "D", 'Đ': "D", 'Ð': "D",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ē': "E", 'Ė': "E", 'Ę': "E", 'Ě': "E",
+	'Ğ': "G", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ī': "I", 'Į': "I", 'İ': "I",
+	'Ł': "L", 'Ñ': "N", 'Ń': "N", 'Ň': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O", 'Ō': "O", 'Ő': "O", 'Œ': "OE",
+	'Ř': "R", 'Ś': "S", 'Š': "S", 'Ş': "S", 'ẞ': "SS", 'Ť': "T", 'Þ': "TH",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ū': "U", 'Ů': "U", 'Ű': "U",
+	'Ý': "Y", 'Ÿ': "Y", 'Ź': "Z", 'Ż': "Z", 'Ž': "Z",
+}
+
+// wordListReason is why a word was dropped, or keepWord if it was kept
+type wordListReason int
+
+const (
+	keepWord wordListReason = iota
+	dropPunctuation
+	dropNonASCII
+	dropNonLetter
+)
+
+// normalizeWord uppercases a word and spells it in the letters A through Z, reporting whether it was
+// transliterated or stripped of punctuation along the way
+func normalizeWord(text string, opts wordListOptions) (word string, transliterated bool, stripped bool, reason wordListReason) {
+	var b strings.Builder
+	for _, r := range text {
+		// ß has no single uppercase letter in common use
+		if r == 'ß' {
+			r = 'ẞ'
+		}
+		r = unicode.ToUpper(r)
+		switch {
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r == '-' || r == '\'' || r == '’':
+			if !opts.StripPunctuation {
+				return "", false, false, dropPunctuation
+			}
+			stripped = true
+		case unicode.IsLetter(r):
+			t, ok := transliterations[r]
+			if !opts.Transliterate || !ok {
+				return "", false, false, dropNonASCII
+			}
+			b.WriteString(t)
+			transliterated = true
+		default:
+			return "", false, false, dropNonLetter
+		}
+	}
+	return b.String(), transliterated, stripped, keepWord
+}
+
+// readWords reads a word list with one word per line, normalizing and filtering each word.
+// Blank lines and lines beginning with '#' are skipped, as is a leading byte order mark.
+// Words are returned in the order read, without duplicates.
+func readWords(r io.Reader, opts wordListOptions) ([]string, wordListStats, error) {
+	var stats wordListStats
+	var words []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		stats.Lines++
+		text := strings.TrimSpace(scanner.Text())
+		if stats.Lines == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if text == "" {
+			stats.Blank++
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			stats.Comments++
+			continue
+		}
+
+		word, transliterated, stripped, reason := normalizeWord(text, opts)
+		switch {
+		case reason == dropPunctuation:
+			stats.Punctuation++
+			continue
+		case reason == dropNonASCII:
+			stats.NonASCII++
+			continue
+		case reason == dropNonLetter || word == "":
+			stats.NonLetters++
+			continue
+		case opts.MinLength > 0 && len(word) < opts.MinLength:
+			stats.TooShort++
+			continue
+		case opts.MaxLength > 0 && len(word) > opts.MaxLength:
+			stats.TooLong++
+			continue
+		case opts.Block[word]:
+			stats.Blocked++
+			continue
+		case opts.Allow != nil && !opts.Allow[word]:
+			stats.NotAllowed++
+			continue
+		case seen[word]:
+			stats.Duplicates++
+			continue
+		}
+
+		seen[word] = true
+		words = append(words, word)
+		stats.Kept++
+		if transliterated {
+			stats.Transliterated++
+		}
+		if stripped {
+			stats.Stripped++
+		}
+	}
+	return words, stats, scanner.Err()
+}
+
+// readWordListFile reads a word list file with readWords
+func readWordListFile(filename string, opts wordListOptions) ([]string, wordListStats, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, wordListStats{}, err
+	}
+	defer file.Close()
+	words, stats, err := readWords(file, opts)
+	if err != nil {
+		return nil, stats, fmt.Errorf("%s: %v", filename, err)
+	}
+	return words, stats, nil
+}
+
+// readWordList reads a word list file with the default options
+func readWordList(dictfile string) ([]string, error) {
+	words, _, err := readWordListFile(dictfile, defaultWordListOptions)
+	return words, err
+}
+
+// readWordSet reads a word list file with the default options into a set, for use as an allowlist or blocklist
+func readWordSet(filename string) (map[string]bool, error) {
+	words, err := readWordList(filename)
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeWord(t *testing.T) {
+	for _, tc := range []struct {
+		text   string
+		opts   wordListOptions
+		word   string
+		reason wordListReason
+	}{
+		{"cat", wordListOptions{}, "CAT", keepWord},
+		{"Café", wordListOptions{Transliterate: true}, "CAFE", keepWord},
+		{"Café", wordListOptions{}, "", dropNonASCII},
+		{"straße", wordListOptions{Transliterate: true}, "STRASSE", keepWord},
+		{"Ærø", wordListOptions{Transliterate: true}, "AERO", keepWord},
+		{"日本", wordListOptions{Transliterate: true}, "", dropNonASCII},
+		{"o'clock", wordListOptions{}, "", dropPunctuation},
+		{"o'clock", wordListOptions{StripPunctuation: true}, "OCLOCK", keepWord},
+		{"rock’n’roll", wordListOptions{StripPunctuation: true}, "ROCKNROLL", keepWord},
+		{"x-ray", wordListOptions{StripPunctuation: true}, "XRAY", keepWord},
+		{"ice cream", wordListOptions{StripPunctuation: true}, "", dropNonLetter},
+		{"r2d2", wordListOptions{}, "", dropNonLetter},
+	} {
+		word, _, _, reason := normalizeWord(tc.text, tc.opts)
+		if word != tc.word || reason != tc.reason {
+			t.Errorf("normalizeWord(%q, %+v) = %q, %d; expected %q, %d", tc.text, tc.opts, word, reason, tc.word, tc.reason)
+		}
+	}
+}
+
+func TestReadWords(t *testing.T) {
+	text := "\ufeffcat\n# a comment\n\n  Dog  \ncafé\nx-ray\nR2D2\nat\nelephant\nCAT\ncow\nemu\n"
+
+	words, stats, err := readWords(strings.NewReader(text), defaultWordListOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"CAT", "DOG", "CAFE", "AT", "ELEPHANT", "COW", "EMU"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("words %v, expected %v", words, expected)
+	}
+	want := wordListStats{Lines: 12, Blank: 1, Comments: 1, Kept: 7, Duplicates: 1, Transliterated: 1, Punctuation: 1, NonLetters: 1}
+	if stats != want {
+		t.Errorf("stats %+v, expected %+v", stats, want)
+	}
+
+	opts := wordListOptions{
+		StripPunctuation: true,
+		MinLength:        3,
+		MaxLength:        5,
+		Allow:            map[string]bool{"CAT": true, "DOG": true, "XRAY": true, "COW": true},
+		Block:            map[string]bool{"COW": true},
+	}
+	words, stats, err = readWords(strings.NewReader(text), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"CAT", "DOG", "XRAY"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("filtered words %v, expected %v", words, expected)
+	}
+	want = wordListStats{Lines: 12, Blank: 1, Comments: 1, Kept: 3, Duplicates: 1, Stripped: 1,
+		NonASCII: 1, NonLetters: 1, TooShort: 1, TooLong: 1, Blocked: 1, NotAllowed: 1}
+	if stats != want {
+		t.Errorf("filtered stats %+v, expected %+v", stats, want)
+	}
+}
+
+func TestLoadDictionaryNormalizes(t *testing.T) {
+	dictfile := filepath.Join(t.TempDir(), "dictionary.txt")
+	if err := ioutil.WriteFile(dictfile, []byte("cat\ncafé\no'clock\nR2D2\nCat\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := loadDictionary(dictfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Has("CAT") || !d.Has("CAFE") {
+		t.Error("CAT and CAFE not in the dictionary")
+	}
+	if d.Words() != 2 {
+		t.Errorf("%d words in the dictionary, expected 2", d.Words())
+	}
+}